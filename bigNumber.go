@@ -448,57 +448,135 @@ func (a *BigNumber) Multiply(b *BigNumber) *BigNumber {
     return resultBigNumber
 }
 
-// Divide divides two BigNumber instances and returns the quotient and remainder.
+// Divide divides two BigNumber instances and returns the quotient truncated
+// to maxDecimal decimal places.
 func (a *BigNumber) Divide(b *BigNumber) *BigNumber {
-	if b.value == "0" {
-        panic("division by zero")
-    }
+	if strings.Trim(b.value, "0"+a.format.decimalSeparator) == "" {
+		panic("division by zero")
+	}
 
-    num1, num2 := a.value, b.value
-    intPart1, decPart1 := splitDecimal(num1, a.format.decimalSeparator)
-    intPart2, decPart2 := splitDecimal(num2, a.format.decimalSeparator)
+	intPart1, decPart1 := splitDecimal(a.value, a.format.decimalSeparator)
+	intPart2, decPart2 := splitDecimal(b.value, b.format.decimalSeparator)
 
-    lenDec1 := len(decPart1)
-    lenDec2 := len(decPart2)
+	// scale both operands to integers with the same number of decimals
+	decimals := max(len(decPart1), len(decPart2))
+	dividend := strings.TrimLeft(intPart1+PadEndString(decPart1, decimals, "0"), "0")
+	divisor := strings.TrimLeft(intPart2+PadEndString(decPart2, decimals, "0"), "0")
+	dividend += strings.Repeat("0", max(a.format.maxDecimal, 0))
 
-    num1 = intPart1 + decPart1
-    num2 = intPart2 + decPart2
+	quotient := ""
+	remainder := ""
+	for i := 0; i < len(dividend); i++ {
+		remainder = strings.TrimLeft(remainder+string(dividend[i]), "0")
+		digit := 0
+		for compareIntegerParts(remainder, divisor) >= 0 {
+			remainder = a.subtractStrings(remainder, divisor)
+			digit++
+		}
+		quotient += a.toNumber(digit)
+	}
 
-    shift := lenDec2 - lenDec1
-    if shift > 0 {
-        num1 += strings.Repeat("0", shift)
-    } else if shift < 0 {
-        num2 += strings.Repeat("0", -shift)
-    }
+	result := insertDecimalPoint(quotient, a.format.maxDecimal, a.format.decimalSeparator)
+	if a.format.maxDecimal > 0 {
+		integerPart, decimalPart := splitDecimal(result, a.format.decimalSeparator)
+		result = strings.Join([]string{integerPart, PadEndString(decimalPart, a.format.maxDecimal, "0")}, a.format.decimalSeparator)
+	}
 
-    result := ""
-    remainder := 0
-    for i := 0; i < len(num1)+a.format.maxDecimal; i++ {
-        var digit1 int
-        if i < len(num1) {
-            digit1 = a.toInteger(string(num1[i]))
-        }
-        remainder = remainder*a.format.base + digit1
-        digitResult := remainder / a.toInteger(string(num2[0]))
-        remainder = remainder % a.toInteger(string(num2[0]))
-        result += a.toNumber(digitResult)
-    }
+	config := *a.format
+	resultBigNumber := NewBigNumber(result)
+	resultBigNumber.format = &config
+	resultBigNumber.sign = a.sign * b.sign
+	return resultBigNumber
+}
 
-    result = insertDecimalPoint(result, a.format.maxDecimal, a.format.decimalSeparator)
-    integerPart,decimalPart := splitDecimal(result,a.format.decimalSeparator)
+// RoundingMode selects how Round handles the discarded digits.
+type RoundingMode int
 
-    result = strings.Join([]string{integerPart,PadEndString(decimalPart,a.format.maxDecimal,"0")},a.format.decimalSeparator)
+const (
+	// RoundHalfUp rounds half away from zero.
+	RoundHalfUp RoundingMode = iota
+	// RoundHalfEven rounds half to the nearest even digit (banker's rounding).
+	RoundHalfEven
+	// RoundDown truncates towards zero.
+	RoundDown
+)
 
-    if(a.format.maxDecimal <= 0){
-        result = strings.TrimRight(result, "0")
-    }
-    if strings.HasSuffix(result, a.format.decimalSeparator) {
-        result = strings.TrimSuffix(result, a.format.decimalSeparator)
-    }
+// Round returns the BigNumber rounded to `places` decimal places, always
+// keeping exactly `places` decimals.
+func (a *BigNumber) Round(places int, mode RoundingMode) *BigNumber {
+	places = max(places, 0)
+	intPart, decPart := splitDecimal(a.value, a.format.decimalSeparator)
+	decPart = PadEndString(decPart, places, "0")
+	kept := intPart + decPart[:places]
+	rest := decPart[places:]
+
+	roundUp := false
+	if len(rest) > 0 {
+		switch mode {
+		case RoundHalfUp:
+			roundUp = a.toInteger(string(rest[0]))*2 >= a.format.base
+		case RoundHalfEven:
+			half := a.toInteger(string(rest[0]))*2 - a.format.base
+			if half > 0 || (half == 0 && strings.Trim(rest[1:], "0") != "") {
+				roundUp = true
+			} else if half == 0 {
+				roundUp = a.toInteger(string(kept[len(kept)-1]))%2 == 1
+			}
+		}
+	}
+	if roundUp {
+		digits := []byte(kept)
+		i := len(digits) - 1
+		for ; i >= 0; i-- {
+			digit := a.toInteger(string(digits[i])) + 1
+			if digit < a.format.base {
+				digits[i] = a.toNumber(digit)[0]
+				break
+			}
+			digits[i] = '0'
+		}
+		kept = string(digits)
+		if i < 0 {
+			kept = "1" + kept
+		}
+	}
 
-    resultBigNumber := NewBigNumber(result)
-    resultBigNumber.sign = a.sign * b.sign
-    return resultBigNumber
+	config := *a.format
+	result := NewBigNumber(insertDecimalPoint(kept, places, a.format.decimalSeparator))
+	result.format = &config
+	if strings.Trim(result.value, "0"+a.format.decimalSeparator) != "" {
+		result.sign = a.sign
+	}
+	return result
+}
+
+// ToFixed formats the BigNumber with exactly `places` decimals, rounding half up.
+func (a *BigNumber) ToFixed(places int) string {
+	return a.Round(places, RoundHalfUp).String()
+}
+
+// ToFormat is ToFixed with the integer part grouped by thousands with the
+// group separator, e.g. "1,234,567.50". A negative `places` keeps every decimal.
+func (a *BigNumber) ToFormat(places int) string {
+	number := a
+	if places >= 0 {
+		number = a.Round(places, RoundHalfUp)
+	}
+	intPart, decPart := splitDecimal(number.value, a.format.decimalSeparator)
+	grouped := ""
+	for i, digit := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			grouped += a.format.groupSeparator
+		}
+		grouped += string(digit)
+	}
+	if decPart != "" {
+		grouped += a.format.decimalSeparator + decPart
+	}
+	if number.sign == -1 && strings.Trim(number.value, "0"+a.format.decimalSeparator) != "" {
+		return "-" + grouped
+	}
+	return grouped
 }
 
 // ExponentiatedBy raises the BigNumber to the power of an integer exponent.
@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ByteFormat configures FormatBytes.
+type ByteFormat struct {
+	// Binary uses the IEC units (KiB, MiB, ...) instead of kB, MB, ...
+	Binary bool
+	// Bits renders the size in bits (kbit, Mbit, ...).
+	Bits bool
+	// Precision is the maximum number of decimals, trailing zeros are dropped.
+	Precision int
+	// Separator is put between the number and the unit.
+	Separator string
+}
+
+func WithBinaryUnits() DataOption[ByteFormat] {
+	return func(f *ByteFormat) {
+		f.Binary = true
+	}
+}
+
+func WithBitUnits() DataOption[ByteFormat] {
+	return func(f *ByteFormat) {
+		f.Bits = true
+	}
+}
+
+func WithBytePrecision(precision int) DataOption[ByteFormat] {
+	return func(f *ByteFormat) {
+		f.Precision = precision
+	}
+}
+
+func WithByteSeparator(separator string) DataOption[ByteFormat] {
+	return func(f *ByteFormat) {
+		f.Separator = separator
+	}
+}
+
+// FormatBytes renders a size given in bytes with the largest unit that keeps
+// the number at or above 1, e.g. FormatBytes(1536, WithBinaryUnits()) is
+// "1.5 KiB" and FormatBytes(1500000) is "1.5 MB".
+func FormatBytes(n int64, opts ...DataOption[ByteFormat]) string {
+	format := ByteFormat{Precision: 2, Separator: " "}
+	for _, opt := range opts {
+		opt(&format)
+	}
+
+	value := float64(n)
+	unit := "B"
+	if format.Bits {
+		value *= 8
+		unit = "bit"
+	}
+	prefixes := DecimalPrefixes
+	step := 1000.0
+	if format.Binary {
+		prefixes = BinaryPrefixes
+		step = 1024
+	}
+
+	sign := ""
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+	precision := max(format.Precision, 0)
+	rounding := math.Pow(10, float64(precision))
+	round := func(v float64) float64 {
+		return math.Round(v*rounding) / rounding
+	}
+
+	index := -1
+	for i, p := range prefixes {
+		if value/p.Factor < 1 {
+			break
+		}
+		index = i
+	}
+	// 999999 B rounds to "1000 kB", move it to the next unit.
+	factor := 1.0
+	if index >= 0 {
+		factor = prefixes[index].Factor
+	}
+	if round(value/factor) >= step && index < len(prefixes)-1 {
+		index++
+	}
+	prefix := ""
+	scaled := value
+	if index >= 0 {
+		prefix = prefixes[index].Symbol
+		scaled = value / prefixes[index].Factor
+	}
+
+	number := strconv.FormatFloat(round(scaled), 'f', precision, 64)
+	if strings.Contains(number, ".") {
+		number = strings.TrimRight(strings.TrimRight(number, "0"), ".")
+	}
+	return sign + number + format.Separator + prefix + unit
+}
+
+// ParseBytes parses a size such as "1.5 GiB", "300kB", "2 MB" or "512" (bytes)
+// and returns it in bytes. Decimal units are powers of 1000, binary units
+// powers of 1024; bit units ("Mbit", "Mb") are divided by 8.
+func ParseBytes(size string) (int64, error) {
+	text := strings.TrimSpace(size)
+	end := 0
+	for end < len(text) && (IsNumberic(rune(text[end])) || text[end] == '.' || (end == 0 && (text[end] == '-' || text[end] == '+'))) {
+		end++
+	}
+	value, err := strconv.ParseFloat(text[:end], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q: %w", size, err)
+	}
+	unit := strings.TrimLeftFunc(text[end:], unicode.IsSpace)
+	if unit == "" {
+		unit = "B"
+	}
+	bytes, err := DefaultUnitRegistry.ConvertIn(DimensionStorage, value, unit, "B")
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q: %w", size, err)
+	}
+	bytes = math.Round(bytes)
+	if bytes >= math.MaxInt64 || bytes < math.MinInt64 {
+		return 0, fmt.Errorf("invalid size %q: out of range", size)
+	}
+	return int64(bytes), nil
+}
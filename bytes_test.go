@@ -0,0 +1,66 @@
+package utils_test
+
+import (
+	"fmt"
+	"testing"
+
+	utils "github.com/jingyuexing/go-utils"
+)
+
+func TestFormatBytes(t *testing.T) {
+	cases := []struct {
+		size   int64
+		opts   []utils.DataOption[utils.ByteFormat]
+		expect string
+	}{
+		{0, nil, "0 B"},
+		{999, nil, "999 B"},
+		{1500, nil, "1.5 kB"},
+		{1536, []utils.DataOption[utils.ByteFormat]{utils.WithBinaryUnits()}, "1.5 KiB"},
+		{999999, nil, "1 MB"},
+		{1073741824, []utils.DataOption[utils.ByteFormat]{utils.WithBinaryUnits()}, "1 GiB"},
+		{1234567, []utils.DataOption[utils.ByteFormat]{utils.WithBytePrecision(0)}, "1 MB"},
+		{1234567, []utils.DataOption[utils.ByteFormat]{utils.WithBytePrecision(3), utils.WithByteSeparator("")}, "1.235MB"},
+		{125000, []utils.DataOption[utils.ByteFormat]{utils.WithBitUnits()}, "1 Mbit"},
+		{-2048, []utils.DataOption[utils.ByteFormat]{utils.WithBinaryUnits()}, "-2 KiB"},
+		{2 * 1000 * 1000 * 1000 * 1000 * 1000 * 1000, nil, "2 EB"},
+	}
+	for _, c := range cases {
+		result := utils.FormatBytes(c.size, c.opts...)
+		if result != c.expect {
+			t.Error(fmt.Sprintf("FormatBytes(%d) Expect: %s, but got %s", c.size, c.expect, result))
+		}
+	}
+}
+
+func TestParseBytes(t *testing.T) {
+	cases := map[string]int64{
+		"512":        512,
+		"1.5 GiB":    1610612736,
+		"300kB":      300000,
+		"2 MB":       2000000,
+		"2 KB":       2000,
+		"1 MiB":      1048576,
+		"8 Mbit":     1000000,
+		"1 Mb":       125000,
+		"1 kb":       125,
+		"1 Gb":       125000000,
+		"1 kibibyte": 1024,
+	}
+	for source, expect := range cases {
+		result, err := utils.ParseBytes(source)
+		if err != nil || result != expect {
+			t.Error(fmt.Sprintf("ParseBytes(%q) Expect: %d, but got %d %v", source, expect, result, err))
+		}
+	}
+	for _, source := range []string{"", "GB", "12 parsecs", "1 km"} {
+		if _, err := utils.ParseBytes(source); err == nil {
+			t.Error(fmt.Sprintf("ParseBytes(%q) should return an error", source))
+		}
+	}
+
+	size, _ := utils.ParseBytes(utils.FormatBytes(1610612736, utils.WithBinaryUnits()))
+	if size != 1610612736 {
+		t.Error(fmt.Sprintf("round trip Expect: %d, but got %d", 1610612736, size))
+	}
+}
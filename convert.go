@@ -1,157 +1,96 @@
 package utils
 
 import (
-	"errors"
 	"math"
 	"strings"
 )
 
-type ConversionFunctions struct{}
+// ConversionFunctions converts values between units of one dimension.
+// All methods look the units up in Registry, or in DefaultUnitRegistry when
+// Registry is nil.
+type ConversionFunctions struct {
+	Registry *UnitRegistry
+}
 
-func (c *ConversionFunctions) Length(value float64, from, to string) float64 {
-	units := map[string]float64{
-		"m":  100,
-		"km": 1000,
-		"cm": 1,
-		"mm": 0.01,
-		"um": 1e-3,
-		"nm": 1e-6,
-		"dm": 10,
-	}
-	_, fromValid := units[from]
-	_, toValid := units[to]
-	if fromValid && toValid {
-		return (value * units[from]) / units[to]
-	} else {
-		return -1
+func (c *ConversionFunctions) registry() *UnitRegistry {
+	if c == nil || c.Registry == nil {
+		return DefaultUnitRegistry
 	}
+	return c.Registry
 }
 
-func (c *ConversionFunctions) Weight(value float64, from, to string) (float64, error) {
-	units := map[string]float64{
-		"g":  1,
-		"kg": 1000,
-		"mg": 0.001,
-		"t":  1000000,
-	}
+// Convert converts value between any two units of the same dimension.
+func (c *ConversionFunctions) Convert(value float64, from, to string) (float64, error) {
+	return c.registry().Convert(value, from, to)
+}
 
-	if _, exists := units[from]; !exists {
-		return 0, errors.New("Invalid weight unit")
-	}
-	if _, exists := units[to]; !exists {
-		return 0, errors.New("Invalid weight unit")
+// convertOr converts within dimension and returns -1 when a unit is unknown.
+func (c *ConversionFunctions) convertOr(dimension string, value float64, from, to string) float64 {
+	result, err := c.registry().ConvertIn(dimension, value, from, to)
+	if err != nil {
+		return -1
 	}
-
-	return (value * units[from]) / units[to], nil
+	return result
 }
 
-func (c *ConversionFunctions) Size(value float64, from, to string) float64 {
-	units := map[string]float64{
-        "":  1,
-        "k": 1000,
-        "w": 10000,
-        "m": 1000000,
-        "b": 1000000000,
-    }
-    from = strings.ToLower(from)
-    to = strings.ToLower(to)
+// Length returns -1 if a unit is unknown, use Convert to get the error.
+func (c *ConversionFunctions) Length(value float64, from, to string) float64 {
+	return c.convertOr(DimensionLength, value, from, to)
+}
 
-    fromFactor, fromValid := units[from]
-    toFactor, toValid := units[to]
+func (c *ConversionFunctions) Weight(value float64, from, to string) (float64, error) {
+	return c.registry().ConvertIn(DimensionMass, value, from, to)
+}
 
-    if fromValid && toValid {
-        return (value * fromFactor) / toFactor
-    } else {
-        return -1
-    }
+// Size converts between count magnitudes ("", "k", "w"/"万", "m", "y"/"亿",
+// "b", "兆"), the unit names are case insensitive. It returns -1 if a unit
+// is unknown.
+func (c *ConversionFunctions) Size(value float64, from, to string) float64 {
+	return c.convertOr(DimensionCount, value, strings.ToLower(from), strings.ToLower(to))
 }
 
+// Volume returns -1 if a unit is unknown, use Convert to get the error.
 func (c *ConversionFunctions) Volume(value float64, from, to string) float64 {
-	units := map[string]float64{
-		"ml": 1,
-		"cl": 10,
-		"dl": 100,
-		"l":  1000,
-	}
-	_, fromValid := units[from]
-	_, toValid := units[to]
-	if fromValid && toValid {
-		return (value * units[from]) / units[to]
-	} else {
-		return -1
-	}
+	return c.convertOr(DimensionVolume, value, from, to)
 }
 
+// Storage converts between bytes and bits with decimal (kB, MB, ... EB) or
+// binary (KiB, MiB, ... EiB) prefixes, "KB" is read as the decimal "kB".
 func (c *ConversionFunctions) Storage(value float64, from, to string) (float64, error) {
-	units := map[string]int{
-		"B":  0,
-		"KB": 1,
-		"MB": 2,
-		"GB": 3,
-		"TB": 4,
-		"PB": 5,
-	}
-	fromIndex, existsFrom := units[from]
-	toIndex, existsTo := units[to]
-
-	if !existsFrom || !existsTo {
-		return 0, errors.New("Invalid storage unit")
-	}
-
-	diff := toIndex - fromIndex
-
-	if diff == 0 {
-		return value, nil
-	}
-
-	factor := math.Pow(1024, math.Abs(float64(diff)))
-	if diff > 0 {
-		return value / factor, nil
-	}
-	return value * factor, nil
+	return c.registry().ConvertIn(DimensionStorage, value, from, to)
 }
 
+// NetSpeed converts between bit rates (bps, kbps, Mbps, Mibps, ...) and
+// byte rates (B/s, kB/s, MiB/s, ...). It returns -1 if a unit is unknown.
 func (c *ConversionFunctions) NetSpeed(speed float64, from, to string) float64 {
-	networkUnitTable := map[string]float64{
-		"bps":  1,
-		"Kbps": 1000,
-		"Mbps": 1000000,
-		"Gbps": 1000000000,
-	}
-
-	fromFactor, existsFrom := networkUnitTable[from]
-	toFactor, existsTo := networkUnitTable[to]
-
-	if !existsFrom || !existsTo {
-		return -1
-	}
-
-	return (speed * fromFactor) / toFactor
+	return c.convertOr(DimensionBitRate, speed, from, to)
 }
 
+// WeightEN converts between imperial and metric mass units ("pounds",
+// "grams", "kgs", "tons", ...). It returns -1 if a unit is unknown.
 func (c *ConversionFunctions) WeightEN(weight float64, from, to string) float64 {
-	unitTable := map[string]float64{
-		"pounds": 1,
-		"grams":  453.592,           // 1 磅 ≈ 453.592 克
-		"kgs":    453.592 / 1000,    // 1 磅 ≈ 0.453592 千克
-		"tons":   453.592 / 1000000, // 1 磅 ≈ 0.000453592 吨
-		"ans":    28.349523125,
-		"kg":     1000,    // 1 千克 = 1000 克
-		"ton":    1000000, // 1 吨 = 1,000,000 克
-	}
-	fromFactor, existsFrom := unitTable[from]
-	toFactor, existsTo := unitTable[to]
+	return c.convertOr(DimensionMass, weight, from, to)
+}
 
-	if !existsFrom || !existsTo {
-		return -1
-	}
+// Temperature converts between K, °C, °F and °R.
+func (c *ConversionFunctions) Temperature(value float64, from, to string) (float64, error) {
+	return c.registry().ConvertIn(DimensionTemperature, value, from, to)
+}
 
-	// 如果 fromUnit 和 toUnit 相同，则直接返回 weight
-	if from == to {
-		return weight
-	}
+// FuelEconomy converts between km/L, L/100km, mpg and mpg(imp). L/100km is
+// inversely proportional to the others, so 0 L/100km has no equivalent.
+func (c *ConversionFunctions) FuelEconomy(value float64, from, to string) (float64, error) {
+	return c.registry().ConvertIn(DimensionFuelEconomy, value, from, to)
+}
+
+// Area converts between m² (and the other area units), ha, acre, 亩 and 顷.
+func (c *ConversionFunctions) Area(value float64, from, to string) (float64, error) {
+	return c.registry().ConvertIn(DimensionArea, value, from, to)
+}
 
-	return (weight * fromFactor) / toFactor
+// Power converts between W (with SI prefixes), hp, dBW and dBm.
+func (c *ConversionFunctions) Power(value float64, from, to string) (float64, error) {
+	return c.registry().ConvertIn(DimensionPower, value, from, to)
 }
 
 func indexOf(slice []string, ele string) int {
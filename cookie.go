@@ -0,0 +1,551 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrInvalidCookie = errors.New("invalid cookie")
+
+// CookieSameSite is the SameSite attribute of a cookie.
+type CookieSameSite string
+
+const (
+	SameSiteDefault CookieSameSite = ""
+	SameSiteLax     CookieSameSite = "Lax"
+	SameSiteStrict  CookieSameSite = "Strict"
+	SameSiteNone    CookieSameSite = "None"
+)
+
+// CookieEntry is a cookie with the attributes of RFC 6265 plus SameSite and
+// Partitioned. A zero Expires is a session cookie. MaxAge follows
+// net/http: 0 means no Max-Age attribute and a negative value deletes the
+// cookie ("Max-Age=0").
+type CookieEntry struct {
+	Name  string
+	Value string
+	// Quoted keeps the double quotes around the value when serializing.
+	Quoted      bool
+	Expires     DateTime
+	MaxAge      int
+	Domain      string
+	Path        string
+	Secure      bool
+	HttpOnly    bool
+	SameSite    CookieSameSite
+	Partitioned bool
+}
+
+// isCookieToken reports whether name is an RFC 2616 token.
+func isCookieToken(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		char := name[i]
+		if char <= ' ' || char >= 0x7f || strings.IndexByte(`()<>@,;:\"/[]?={}`, char) >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// isCookieOctet reports whether char may appear in a cookie value.
+func isCookieOctet(char byte) bool {
+	return char == 0x21 || (char >= 0x23 && char <= 0x2b) || (char >= 0x2d && char <= 0x3a) ||
+		(char >= 0x3c && char <= 0x5b) || (char >= 0x5d && char <= 0x7e)
+}
+
+func unquoteCookieValue(value string) (string, bool) {
+	if len(value) > 1 && value[0] == '"' && value[len(value)-1] == '"' {
+		return value[1 : len(value)-1], true
+	}
+	return value, false
+}
+
+// ParseCookieHeader parses the value of a Cookie request header, such as
+// `a=1; b="two"`. Pairs without a valid name are skipped.
+func ParseCookieHeader(header string) []CookieEntry {
+	cookies := []CookieEntry{}
+	for _, pair := range strings.Split(header, ";") {
+		name, value, _ := strings.Cut(strings.TrimSpace(pair), "=")
+		name = strings.TrimSpace(name)
+		if !isCookieToken(name) {
+			continue
+		}
+		value, quoted := unquoteCookieValue(strings.TrimSpace(value))
+		cookies = append(cookies, CookieEntry{Name: name, Value: value, Quoted: quoted})
+	}
+	return cookies
+}
+
+var cookieTimeFormats = []string{
+	http.TimeFormat,
+	time.RFC1123,
+	"Mon, 02-Jan-2006 15:04:05 MST",
+	time.RFC850,
+	time.ANSIC,
+}
+
+func parseCookieTime(value string) (time.Time, bool) {
+	for _, layout := range cookieTimeFormats {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// ParseSetCookie parses a Set-Cookie header following RFC 6265 section 5.2:
+// unknown attributes and attributes with invalid values are ignored.
+func ParseSetCookie(line string) (CookieEntry, error) {
+	parts := strings.Split(line, ";")
+	name, value, ok := strings.Cut(parts[0], "=")
+	name = strings.TrimSpace(name)
+	if !ok || !isCookieToken(name) {
+		return CookieEntry{}, fmt.Errorf("%w: %q", ErrInvalidCookie, line)
+	}
+	cookie := CookieEntry{Name: name}
+	cookie.Value, cookie.Quoted = unquoteCookieValue(strings.TrimSpace(value))
+
+	for _, attribute := range parts[1:] {
+		key, value, _ := strings.Cut(attribute, "=")
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "expires":
+			if date, ok := parseCookieTime(value); ok {
+				cookie.Expires = From(date)
+			}
+		case "max-age":
+			seconds, err := strconv.Atoi(value)
+			if err != nil {
+				continue
+			}
+			if seconds <= 0 {
+				seconds = -1
+			}
+			cookie.MaxAge = seconds
+		case "domain":
+			cookie.Domain = strings.ToLower(strings.TrimPrefix(value, "."))
+		case "path":
+			if strings.HasPrefix(value, "/") {
+				cookie.Path = value
+			}
+		case "secure":
+			cookie.Secure = true
+		case "httponly":
+			cookie.HttpOnly = true
+		case "partitioned":
+			cookie.Partitioned = true
+		case "samesite":
+			switch strings.ToLower(value) {
+			case "lax":
+				cookie.SameSite = SameSiteLax
+			case "strict":
+				cookie.SameSite = SameSiteStrict
+			case "none":
+				cookie.SameSite = SameSiteNone
+			}
+		}
+	}
+	return cookie, nil
+}
+
+// Validate checks the name, the value and the domain of the cookie.
+func (c CookieEntry) Validate() error {
+	if !isCookieToken(c.Name) {
+		return fmt.Errorf("%w: invalid name %q", ErrInvalidCookie, c.Name)
+	}
+	for i := 0; i < len(c.Value); i++ {
+		if !isCookieOctet(c.Value[i]) && c.Value[i] != ' ' && c.Value[i] != ',' {
+			return fmt.Errorf("%w: invalid byte %q in the value of %q", ErrInvalidCookie, c.Value[i], c.Name)
+		}
+	}
+	if strings.ContainsAny(c.Domain, ";, ") || strings.ContainsAny(c.Path, ";") {
+		return fmt.Errorf("%w: invalid domain or path for %q", ErrInvalidCookie, c.Name)
+	}
+	return nil
+}
+
+func (c CookieEntry) pair() string {
+	if c.Quoted || strings.ContainsAny(c.Value, " ,") {
+		return c.Name + `="` + c.Value + `"`
+	}
+	return c.Name + "=" + c.Value
+}
+
+// String serializes the cookie as a Set-Cookie header value, with the
+// attributes always in the same order.
+func (c CookieEntry) String() string {
+	parts := []string{c.pair()}
+	if c.Path != "" {
+		parts = append(parts, "Path="+c.Path)
+	}
+	if c.Domain != "" {
+		parts = append(parts, "Domain="+c.Domain)
+	}
+	if !c.Expires.RawTime().IsZero() {
+		parts = append(parts, "Expires="+c.Expires.RawTime().UTC().Format(http.TimeFormat))
+	}
+	if c.MaxAge > 0 {
+		parts = append(parts, "Max-Age="+strconv.Itoa(c.MaxAge))
+	} else if c.MaxAge < 0 {
+		parts = append(parts, "Max-Age=0")
+	}
+	if c.HttpOnly {
+		parts = append(parts, "HttpOnly")
+	}
+	if c.Secure {
+		parts = append(parts, "Secure")
+	}
+	if c.SameSite != SameSiteDefault {
+		parts = append(parts, "SameSite="+string(c.SameSite))
+	}
+	if c.Partitioned {
+		parts = append(parts, "Partitioned")
+	}
+	return strings.Join(parts, "; ")
+}
+
+// HTTPCookie converts the cookie to a net/http cookie. net/http has no
+// Partitioned field before Go 1.23, it is kept in Unparsed.
+func (c CookieEntry) HTTPCookie() *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     c.Name,
+		Value:    c.Value,
+		Path:     c.Path,
+		Domain:   c.Domain,
+		MaxAge:   c.MaxAge,
+		Secure:   c.Secure,
+		HttpOnly: c.HttpOnly,
+	}
+	if !c.Expires.RawTime().IsZero() {
+		cookie.Expires = *c.Expires.RawTime()
+	}
+	switch c.SameSite {
+	case SameSiteLax:
+		cookie.SameSite = http.SameSiteLaxMode
+	case SameSiteStrict:
+		cookie.SameSite = http.SameSiteStrictMode
+	case SameSiteNone:
+		cookie.SameSite = http.SameSiteNoneMode
+	}
+	if c.Partitioned {
+		cookie.Unparsed = append(cookie.Unparsed, "Partitioned")
+	}
+	return cookie
+}
+
+// CookieFromHTTP converts a net/http cookie.
+func CookieFromHTTP(cookie *http.Cookie) CookieEntry {
+	entry := CookieEntry{
+		Name:     cookie.Name,
+		Value:    cookie.Value,
+		Path:     cookie.Path,
+		Domain:   strings.TrimPrefix(cookie.Domain, "."),
+		MaxAge:   cookie.MaxAge,
+		Secure:   cookie.Secure,
+		HttpOnly: cookie.HttpOnly,
+	}
+	if !cookie.Expires.IsZero() {
+		entry.Expires = From(cookie.Expires)
+	}
+	switch cookie.SameSite {
+	case http.SameSiteLaxMode:
+		entry.SameSite = SameSiteLax
+	case http.SameSiteStrictMode:
+		entry.SameSite = SameSiteStrict
+	case http.SameSiteNoneMode:
+		entry.SameSite = SameSiteNone
+	}
+	for _, attribute := range cookie.Unparsed {
+		if strings.EqualFold(strings.TrimSpace(attribute), "Partitioned") {
+			entry.Partitioned = true
+		}
+	}
+	return entry
+}
+
+type jarCookie struct {
+	CookieEntry
+	hostOnly bool
+	expires  time.Time
+	created  time.Time
+}
+
+// CookieJar stores cookies following the storage model of RFC 6265. It
+// implements http.CookieJar, so it can be used by an http.Client.
+type CookieJar struct {
+	mutex    sync.RWMutex
+	cookies  []*jarCookie
+	now      func() time.Time
+	suffixes cookiejar.PublicSuffixList
+}
+
+// WithJarClock replaces time.Now for expiry checks.
+func WithJarClock(now func() time.Time) DataOption[CookieJar] {
+	return func(j *CookieJar) {
+		j.now = now
+	}
+}
+
+// WithPublicSuffixList rejects cookies set for a public suffix such as
+// "co.uk", golang.org/x/net/publicsuffix provides a full list. Without a
+// list only single label domains like "com" are rejected.
+func WithPublicSuffixList(list cookiejar.PublicSuffixList) DataOption[CookieJar] {
+	return func(j *CookieJar) {
+		j.suffixes = list
+	}
+}
+
+func NewCookieJar(opts ...DataOption[CookieJar]) *CookieJar {
+	jar := &CookieJar{now: time.Now}
+	for _, opt := range opts {
+		opt(jar)
+	}
+	return jar
+}
+
+// defaultCookiePath is the default-path of RFC 6265 section 5.1.4.
+func defaultCookiePath(path string) string {
+	if !strings.HasPrefix(path, "/") {
+		return "/"
+	}
+	last := strings.LastIndex(path, "/")
+	if last == 0 {
+		return "/"
+	}
+	return path[:last]
+}
+
+func cookieDomainMatch(host string, domain string) bool {
+	if host == domain {
+		return true
+	}
+	return strings.HasSuffix(host, "."+domain) && net.ParseIP(host) == nil
+}
+
+func cookiePathMatch(requestPath string, cookiePath string) bool {
+	if requestPath == cookiePath {
+		return true
+	}
+	if strings.HasPrefix(requestPath, cookiePath) {
+		return strings.HasSuffix(cookiePath, "/") || requestPath[len(cookiePath)] == '/'
+	}
+	return false
+}
+
+func cookieHost(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+func (j *CookieJar) isPublicSuffix(domain string) bool {
+	if j.suffixes != nil {
+		return j.suffixes.PublicSuffix(domain) == domain
+	}
+	return !strings.Contains(domain, ".")
+}
+
+// Set stores cookie as if it was received from u, which may be nil for
+// cookies that are not bound to a host. A cookie for a foreign domain is
+// rejected. Expired cookies and cookies with a negative MaxAge delete the
+// stored cookie with the same name, domain and path.
+func (j *CookieJar) Set(u *url.URL, cookie CookieEntry) error {
+	if err := cookie.Validate(); err != nil {
+		return err
+	}
+	host := cookieHost(u)
+	entry := &jarCookie{CookieEntry: cookie, created: j.now()}
+	entry.Domain = strings.ToLower(strings.TrimPrefix(cookie.Domain, "."))
+	if entry.Domain != "" && j.isPublicSuffix(entry.Domain) {
+		// RFC 6265 section 5.3 step 5: a public suffix is only allowed as
+		// the host itself, and the cookie becomes host-only
+		if entry.Domain != host {
+			return fmt.Errorf("%w: domain %q is a public suffix", ErrInvalidCookie, cookie.Domain)
+		}
+		entry.Domain = ""
+	}
+	if entry.Domain == "" {
+		entry.Domain, entry.hostOnly = host, true
+	} else if host != "" && !cookieDomainMatch(host, entry.Domain) {
+		return fmt.Errorf("%w: domain %q does not match %q", ErrInvalidCookie, cookie.Domain, host)
+	}
+	if entry.Path == "" {
+		path := "/"
+		if u != nil {
+			path = u.EscapedPath()
+		}
+		entry.Path = defaultCookiePath(path)
+	}
+	switch {
+	case cookie.MaxAge < 0:
+		entry.expires = time.Unix(1, 0)
+	case cookie.MaxAge > 0:
+		entry.expires = entry.created.Add(time.Duration(cookie.MaxAge) * time.Second)
+	case !cookie.Expires.RawTime().IsZero():
+		entry.expires = *cookie.Expires.RawTime()
+	}
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	for i, stored := range j.cookies {
+		if stored.Name == entry.Name && stored.Domain == entry.Domain && stored.Path == entry.Path {
+			// the replacement keeps the creation time of the old cookie
+			entry.created = stored.created
+			j.cookies = append(j.cookies[:i], j.cookies[i+1:]...)
+			break
+		}
+	}
+	if entry.expires.IsZero() || entry.expires.After(j.now()) {
+		j.cookies = append(j.cookies, entry)
+	}
+	return nil
+}
+
+// ParseSetCookie stores the cookies of Set-Cookie header lines received from u.
+func (j *CookieJar) ParseSetCookie(u *url.URL, lines ...string) error {
+	for _, line := range lines {
+		cookie, err := ParseSetCookie(line)
+		if err != nil {
+			return err
+		}
+		if err := j.Set(u, cookie); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseCookie stores the cookies of a Cookie request header received by u.
+func (j *CookieJar) ParseCookie(u *url.URL, header string) error {
+	for _, cookie := range ParseCookieHeader(header) {
+		if err := j.Set(u, cookie); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Get returns the first cookie called name that is not expired.
+func (j *CookieJar) Get(name string) (CookieEntry, bool) {
+	for _, cookie := range j.All() {
+		if cookie.Name == name {
+			return cookie, true
+		}
+	}
+	return CookieEntry{}, false
+}
+
+// Delete removes every cookie called name.
+func (j *CookieJar) Delete(name string) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	kept := j.cookies[:0]
+	for _, cookie := range j.cookies {
+		if cookie.Name != name {
+			kept = append(kept, cookie)
+		}
+	}
+	j.cookies = kept
+}
+
+// sorted returns the cookies that match filter in the order of RFC 6265
+// section 5.4: longer paths first, then older cookies first.
+func (j *CookieJar) sorted(filter func(cookie *jarCookie) bool) []*jarCookie {
+	j.mutex.RLock()
+	defer j.mutex.RUnlock()
+	now := j.now()
+	result := []*jarCookie{}
+	for _, cookie := range j.cookies {
+		if (cookie.expires.IsZero() || cookie.expires.After(now)) && filter(cookie) {
+			result = append(result, cookie)
+		}
+	}
+	sort.SliceStable(result, func(a, b int) bool {
+		if len(result[a].Path) != len(result[b].Path) {
+			return len(result[a].Path) > len(result[b].Path)
+		}
+		return result[a].created.Before(result[b].created)
+	})
+	return result
+}
+
+// All returns every stored cookie that is not expired.
+func (j *CookieJar) All() []CookieEntry {
+	cookies := []CookieEntry{}
+	for _, cookie := range j.sorted(func(*jarCookie) bool { return true }) {
+		cookies = append(cookies, cookie.CookieEntry)
+	}
+	return cookies
+}
+
+// matching returns the cookies to send to u, a nil u only gets the cookies
+// that were set without a host.
+func (j *CookieJar) matching(u *url.URL) []*jarCookie {
+	host := cookieHost(u)
+	scheme, path := "", "/"
+	if u != nil {
+		scheme = u.Scheme
+		if u.EscapedPath() != "" {
+			path = u.EscapedPath()
+		}
+	}
+	return j.sorted(func(cookie *jarCookie) bool {
+		if cookie.hostOnly && cookie.Domain != host {
+			return false
+		}
+		if !cookie.hostOnly && !cookieDomainMatch(host, cookie.Domain) {
+			return false
+		}
+		if cookie.Secure && scheme != "https" && scheme != "wss" {
+			return false
+		}
+		return cookiePathMatch(path, cookie.Path)
+	})
+}
+
+// CookieHeader returns the Cookie request header to send to u.
+func (j *CookieJar) CookieHeader(u *url.URL) string {
+	pairs := []string{}
+	for _, cookie := range j.matching(u) {
+		pairs = append(pairs, cookie.pair())
+	}
+	return strings.Join(pairs, "; ")
+}
+
+// SetCookieHeaders serializes every stored cookie as a Set-Cookie header value.
+func (j *CookieJar) SetCookieHeaders() []string {
+	headers := []string{}
+	for _, cookie := range j.All() {
+		headers = append(headers, cookie.String())
+	}
+	return headers
+}
+
+// SetCookies implements http.CookieJar.
+func (j *CookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	for _, cookie := range cookies {
+		j.Set(u, CookieFromHTTP(cookie))
+	}
+}
+
+// Cookies implements http.CookieJar.
+func (j *CookieJar) Cookies(u *url.URL) []*http.Cookie {
+	cookies := []*http.Cookie{}
+	for _, cookie := range j.matching(u) {
+		cookies = append(cookies, &http.Cookie{Name: cookie.Name, Value: cookie.Value})
+	}
+	return cookies
+}
@@ -0,0 +1,197 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrCookieExpired = errors.New("cookie expired")
+	ErrCookieForged  = errors.New("cookie signature or ciphertext is invalid")
+	ErrCookieNoKey   = errors.New("cookie codec needs at least one key")
+)
+
+// SecureCookie signs and encrypts single cookie values. The first key is
+// used to sign and encrypt, every key is tried when verifying, so keys can
+// be rotated by prepending the new one. The cookie name is bound to the
+// value, a value can not be moved to another cookie.
+type SecureCookie struct {
+	// signKeys and encryptKeys are derived from each configured key, so
+	// the HMAC and AES-GCM never share a key.
+	signKeys    [][]byte
+	encryptKeys [][]byte
+	maxAge      time.Duration
+	now         func() time.Time
+}
+
+// WithCookieMaxAge embeds an expiry of now+maxAge in every value. Zero
+// means the values never expire.
+func WithCookieMaxAge(maxAge time.Duration) DataOption[SecureCookie] {
+	return func(s *SecureCookie) {
+		s.maxAge = maxAge
+	}
+}
+
+// WithCookieClock replaces time.Now for expiry stamps and checks.
+func WithCookieClock(now func() time.Time) DataOption[SecureCookie] {
+	return func(s *SecureCookie) {
+		s.now = now
+	}
+}
+
+// NewSecureCookie creates a codec, keys are ordered from the newest to the
+// oldest. Encryption needs keys of 16, 24 or at least 32 bytes, selecting
+// AES-128, AES-192 or AES-256.
+func NewSecureCookie(keys [][]byte, opts ...DataOption[SecureCookie]) (*SecureCookie, error) {
+	if len(keys) == 0 {
+		return nil, ErrCookieNoKey
+	}
+	for _, key := range keys {
+		if len(key) == 0 {
+			return nil, ErrCookieNoKey
+		}
+	}
+	secure := &SecureCookie{now: time.Now}
+	for _, key := range keys {
+		secure.signKeys = append(secure.signKeys, cookieSubkey(key, "sign"))
+		encrypt := cookieSubkey(key, "encrypt")
+		secure.encryptKeys = append(secure.encryptKeys, encrypt[:min(len(key), len(encrypt))])
+	}
+	for _, opt := range opts {
+		opt(secure)
+	}
+	return secure, nil
+}
+
+func (s *SecureCookie) expiry() int64 {
+	if s.maxAge <= 0 {
+		return 0
+	}
+	return s.now().Add(s.maxAge).Unix()
+}
+
+func (s *SecureCookie) checkExpiry(name string, expires int64) error {
+	if expires != 0 && s.now().Unix() >= expires {
+		return fmt.Errorf("%w: %s at %s", ErrCookieExpired, name, time.Unix(expires, 0).UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// cookieSubkey derives the key for one purpose from a configured key.
+func cookieSubkey(key []byte, label string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("go-utils secure cookie " + label))
+	return mac.Sum(nil)
+}
+
+func cookieMAC(key []byte, name string, payload string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(name))
+	mac.Write([]byte{0})
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+// Sign returns value with its expiry and an HMAC-SHA256 signature, as
+// "base64(value).expiry.base64(mac)".
+func (s *SecureCookie) Sign(name string, value string) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(value)) + "." + strconv.FormatInt(s.expiry(), 10)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(cookieMAC(s.signKeys[0], name, payload))
+}
+
+// Verify checks a value made by Sign and returns the original value.
+func (s *SecureCookie) Verify(name string, signed string) (string, error) {
+	last := strings.LastIndex(signed, ".")
+	if last < 0 {
+		return "", fmt.Errorf("%w: %s", ErrCookieForged, name)
+	}
+	payload := signed[:last]
+	signature, err := base64.RawURLEncoding.DecodeString(signed[last+1:])
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrCookieForged, name)
+	}
+	valid := false
+	for _, key := range s.signKeys {
+		if hmac.Equal(signature, cookieMAC(key, name, payload)) {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return "", fmt.Errorf("%w: %s", ErrCookieForged, name)
+	}
+	encoded, stamp, _ := strings.Cut(payload, ".")
+	expires, err := strconv.ParseInt(stamp, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrCookieForged, name)
+	}
+	if err := s.checkExpiry(name, expires); err != nil {
+		return "", err
+	}
+	value, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrCookieForged, name)
+	}
+	return string(value), nil
+}
+
+func cookieAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Encrypt seals value and its expiry with AES-GCM, using the cookie name as
+// additional data. The result is URL safe base64 of nonce and ciphertext.
+func (s *SecureCookie) Encrypt(name string, value string) (string, error) {
+	aead, err := cookieAEAD(s.encryptKeys[0])
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	plain := make([]byte, 8, 8+len(value))
+	binary.BigEndian.PutUint64(plain, uint64(s.expiry()))
+	plain = append(plain, value...)
+	return base64.RawURLEncoding.EncodeToString(aead.Seal(nonce, nonce, plain, []byte(name))), nil
+}
+
+// Decrypt opens a value made by Encrypt and returns the original value.
+func (s *SecureCookie) Decrypt(name string, sealed string) (string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrCookieForged, name)
+	}
+	for _, key := range s.encryptKeys {
+		aead, err := cookieAEAD(key)
+		if err != nil {
+			return "", err
+		}
+		if len(data) < aead.NonceSize() {
+			break
+		}
+		plain, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], []byte(name))
+		if err != nil || len(plain) < 8 {
+			continue
+		}
+		if err := s.checkExpiry(name, int64(binary.BigEndian.Uint64(plain))); err != nil {
+			return "", err
+		}
+		return string(plain[8:]), nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrCookieForged, name)
+}
@@ -0,0 +1,88 @@
+package utils_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	utils "github.com/jingyuexing/go-utils"
+)
+
+func TestSecureCookieSign(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := utils.WithCookieClock(func() time.Time { return now })
+	old, _ := utils.NewSecureCookie([][]byte{[]byte("old-key")}, clock, utils.WithCookieMaxAge(time.Hour))
+	signed := old.Sign("session", "user=1; admin")
+
+	rotated, _ := utils.NewSecureCookie([][]byte{[]byte("new-key"), []byte("old-key")}, clock)
+	if value, err := rotated.Verify("session", signed); err != nil || value != "user=1; admin" {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s (%v)", "user=1; admin", value, err))
+	}
+	fresh, _ := utils.NewSecureCookie([][]byte{[]byte("new-key")}, clock)
+	if _, err := fresh.Verify("session", signed); !errors.Is(err, utils.ErrCookieForged) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrCookieForged, err))
+	}
+	if _, err := rotated.Verify("other", signed); !errors.Is(err, utils.ErrCookieForged) {
+		t.Error(fmt.Sprintf("Expect: %v for another cookie name, but got %v", utils.ErrCookieForged, err))
+	}
+	tampered := strings.Replace(signed, ".", "x.", 1)
+	if _, err := rotated.Verify("session", tampered); !errors.Is(err, utils.ErrCookieForged) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrCookieForged, err))
+	}
+
+	now = now.Add(time.Hour)
+	if _, err := rotated.Verify("session", signed); !errors.Is(err, utils.ErrCookieExpired) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrCookieExpired, err))
+	}
+	if _, err := utils.NewSecureCookie(nil); !errors.Is(err, utils.ErrCookieNoKey) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrCookieNoKey, err))
+	}
+}
+
+func TestSecureCookieEncrypt(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := utils.WithCookieClock(func() time.Time { return now })
+	oldKey, newKey := []byte(strings.Repeat("a", 32)), []byte(strings.Repeat("b", 16))
+	old, _ := utils.NewSecureCookie([][]byte{oldKey}, clock, utils.WithCookieMaxAge(time.Minute))
+	sealed, err := old.Encrypt("hint", "cart=3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(sealed, "cart") {
+		t.Error(fmt.Sprintf("Expect: an encrypted value, but got %s", sealed))
+	}
+	cookie := new(utils.Cookie)
+	cookie.NewCookie("", ";", "=")
+	cookie.PutOne("hint", sealed)
+	parsed := utils.ParseCookieHeader(cookie.ToString())
+
+	rotated, _ := utils.NewSecureCookie([][]byte{newKey, oldKey}, clock)
+	if value, err := rotated.Decrypt("hint", parsed[0].Value); err != nil || value != "cart=3" {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s (%v)", "cart=3", value, err))
+	}
+	if _, err := rotated.Decrypt("other", sealed); !errors.Is(err, utils.ErrCookieForged) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrCookieForged, err))
+	}
+	if _, err := rotated.Decrypt("hint", "AAAA"+sealed[4:]); !errors.Is(err, utils.ErrCookieForged) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrCookieForged, err))
+	}
+	now = now.Add(time.Minute)
+	if _, err := rotated.Decrypt("hint", sealed); !errors.Is(err, utils.ErrCookieExpired) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrCookieExpired, err))
+	}
+
+	signed := old.Sign("hint", "cart=3")
+	if _, err := old.Decrypt("hint", signed); !errors.Is(err, utils.ErrCookieForged) {
+		t.Error(fmt.Sprintf("Expect: %v for a signed value, but got %v", utils.ErrCookieForged, err))
+	}
+	if _, err := old.Verify("hint", sealed); !errors.Is(err, utils.ErrCookieForged) {
+		t.Error(fmt.Sprintf("Expect: %v for an encrypted value, but got %v", utils.ErrCookieForged, err))
+	}
+
+	short, _ := utils.NewSecureCookie([][]byte{[]byte("short")})
+	if _, err := short.Encrypt("hint", "x"); err == nil {
+		t.Error("Expect: an error for an invalid AES key, but got nil")
+	}
+}
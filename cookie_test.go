@@ -0,0 +1,170 @@
+package utils_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	utils "github.com/jingyuexing/go-utils"
+)
+
+func TestParseCookieHeader(t *testing.T) {
+	cookies := utils.ParseCookieHeader(` a=1; b="two words"; broken; =x; c=`)
+	got := []string{}
+	for _, cookie := range cookies {
+		got = append(got, cookie.Name+":"+cookie.Value)
+	}
+	if strings.Join(got, ",") != "a:1,b:two words,broken:,c:" {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s", "a:1,b:two words,broken:,c:", got))
+	}
+	if !cookies[1].Quoted {
+		t.Error("Expect: b to be quoted")
+	}
+}
+
+func TestParseSetCookie(t *testing.T) {
+	line := "id=a3fWa; Expires=Wed, 21-Oct-2015 07:28:00 GMT; Max-Age=3600; Domain=.Example.com; " +
+		"Path=/docs; Secure; HttpOnly; SameSite=lax; Partitioned; Unknown=1"
+	cookie, err := utils.ParseSetCookie(line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cookie.Name != "id" || cookie.Value != "a3fWa" || cookie.MaxAge != 3600 || cookie.Domain != "example.com" ||
+		cookie.Path != "/docs" || !cookie.Secure || !cookie.HttpOnly || cookie.SameSite != utils.SameSiteLax || !cookie.Partitioned {
+		t.Error(fmt.Sprintf("Expect: every attribute parsed, but got %+v", cookie))
+	}
+	if cookie.Expires.Format("YYYY-MM-DD HH:mm") != "2015-10-21 07:28" {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s", "2015-10-21 07:28", cookie.Expires.Format("YYYY-MM-DD HH:mm")))
+	}
+	expected := "id=a3fWa; Path=/docs; Domain=example.com; Expires=Wed, 21 Oct 2015 07:28:00 GMT; Max-Age=3600; " +
+		"HttpOnly; Secure; SameSite=Lax; Partitioned"
+	if cookie.String() != expected {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s", expected, cookie.String()))
+	}
+
+	if cookie, _ := utils.ParseSetCookie("a=1; Max-Age=0; Path=relative"); cookie.MaxAge != -1 || cookie.Path != "" {
+		t.Error(fmt.Sprintf("Expect: MaxAge -1 and no path, but got %+v", cookie))
+	}
+	if _, err := utils.ParseSetCookie("no joiner"); err == nil {
+		t.Error("Expect: an error for a cookie without a name, but got nil")
+	}
+
+	httpCookie := cookie.HTTPCookie()
+	if httpCookie.String() != "id=a3fWa; Path=/docs; Domain=example.com; Expires=Wed, 21 Oct 2015 07:28:00 GMT; Max-Age=3600; HttpOnly; Secure; SameSite=Lax" {
+		t.Error(fmt.Sprintf("Expect: an equivalent net/http cookie, but got %s", httpCookie.String()))
+	}
+	if back := utils.CookieFromHTTP(httpCookie); back.String() != expected {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s", expected, back.String()))
+	}
+}
+
+func TestCookieJar(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	jar := utils.NewCookieJar(utils.WithJarClock(func() time.Time { return now }))
+	site, _ := url.Parse("https://www.example.com/docs/index.html")
+	err := jar.ParseSetCookie(site,
+		"session=1; Secure",
+		"theme=dark; Path=/; Domain=example.com",
+		"page=2; Path=/docs/; Max-Age=60",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := jar.ParseSetCookie(site, "x=1; Domain=other.com"); err == nil {
+		t.Error("Expect: an error for a foreign domain, but got nil")
+	}
+	if err := jar.Set(site, utils.CookieEntry{Name: "x", Value: "1", Domain: "com"}); !errors.Is(err, utils.ErrInvalidCookie) {
+		t.Error(fmt.Sprintf("Expect: %v for a public suffix, but got %v", utils.ErrInvalidCookie, err))
+	}
+
+	if got := jar.CookieHeader(site); got != "page=2; session=1; theme=dark" {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s", "page=2; session=1; theme=dark", got))
+	}
+	plain, _ := url.Parse("http://api.example.com/")
+	if got := jar.CookieHeader(plain); got != "theme=dark" {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s", "theme=dark", got))
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, ok := jar.Get("page"); ok {
+		t.Error("Expect: page to expire after its Max-Age")
+	}
+	jar.ParseSetCookie(site, "theme=gone; Domain=example.com; Path=/; Max-Age=0")
+	if got := jar.CookieHeader(site); got != "session=1" {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s", "session=1", got))
+	}
+	jar.Delete("session")
+	if len(jar.All()) != 0 {
+		t.Error(fmt.Sprintf("Expect: an empty jar, but got %v", jar.SetCookieHeaders()))
+	}
+
+	jar.Set(site, utils.CookieEntry{Name: "upper", Value: "1", Domain: ".Example.COM"})
+	if cookie, _ := jar.Get("upper"); cookie.Domain != "example.com" {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s", "example.com", cookie.Domain))
+	}
+	jar.Set(nil, utils.CookieEntry{Name: "loose", Value: "1"})
+	if got := jar.CookieHeader(nil); got != "loose=1" {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s", "loose=1", got))
+	}
+}
+
+type cookieSuffixes struct{}
+
+func (cookieSuffixes) PublicSuffix(domain string) string {
+	if strings.HasSuffix(domain, ".co.uk") || domain == "co.uk" {
+		return "co.uk"
+	}
+	return domain[strings.LastIndex(domain, ".")+1:]
+}
+
+func (cookieSuffixes) String() string {
+	return "test suffixes"
+}
+
+func TestCookieJarPublicSuffix(t *testing.T) {
+	jar := utils.NewCookieJar(utils.WithPublicSuffixList(cookieSuffixes{}))
+	site, _ := url.Parse("https://shop.example.co.uk/")
+	if err := jar.ParseSetCookie(site, "a=1; Domain=co.uk"); !errors.Is(err, utils.ErrInvalidCookie) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrInvalidCookie, err))
+	}
+	if err := jar.ParseSetCookie(site, "b=2; Domain=example.co.uk"); err != nil {
+		t.Error(err)
+	}
+	suffix, _ := url.Parse("https://co.uk/")
+	if err := jar.ParseSetCookie(suffix, "c=3; Domain=co.uk"); err != nil {
+		t.Error(err)
+	}
+	other, _ := url.Parse("https://other.co.uk/")
+	if got := jar.CookieHeader(other); got != "" {
+		t.Error(fmt.Sprintf("Expect: no cookies for other.co.uk, but got %s", got))
+	}
+}
+
+func TestCookieJarHTTP(t *testing.T) {
+	var _ http.CookieJar = utils.NewCookieJar()
+	jar := utils.NewCookieJar()
+	reference, _ := cookiejar.New(nil)
+	site, _ := url.Parse("http://example.com/a/b")
+	cookies := []*http.Cookie{
+		{Name: "a", Value: "1"},
+		{Name: "b", Value: "2", Path: "/"},
+		{Name: "c", Value: "3", Path: "/other"},
+	}
+	jar.SetCookies(site, cookies)
+	reference.SetCookies(site, cookies)
+	got, expected := []string{}, []string{}
+	for _, cookie := range jar.Cookies(site) {
+		got = append(got, cookie.String())
+	}
+	for _, cookie := range reference.Cookies(site) {
+		expected = append(expected, cookie.String())
+	}
+	if strings.Join(got, ";") != strings.Join(expected, ";") {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s", expected, got))
+	}
+}
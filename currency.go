@@ -0,0 +1,301 @@
+package utils
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrRateNotFound  = errors.New("exchange rate not found")
+	ErrInvalidAmount = errors.New("invalid amount")
+)
+
+// currencyMinorUnits lists the ISO 4217 currencies whose minor unit is not 2.
+var currencyMinorUnits = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+var currencyMutex sync.RWMutex
+
+// CurrencyMinorUnits returns the number of decimals of an ISO 4217 currency,
+// 2 for currencies that are not listed.
+func CurrencyMinorUnits(code string) int {
+	currencyMutex.RLock()
+	defer currencyMutex.RUnlock()
+	if digits, ok := currencyMinorUnits[strings.ToUpper(code)]; ok {
+		return digits
+	}
+	return 2
+}
+
+// RegisterCurrency sets the number of decimals used when rounding amounts of code.
+func RegisterCurrency(code string, minorUnits int) {
+	currencyMutex.Lock()
+	defer currencyMutex.Unlock()
+	currencyMinorUnits[strings.ToUpper(code)] = minorUnits
+}
+
+// RateProvider supplies exchange rates. Rate returns how many units of quote
+// one unit of base buys on the given date; a zero DateTime asks for the
+// latest known rate.
+type RateProvider interface {
+	Rate(base, quote string, date DateTime) (*BigNumber, error)
+}
+
+func isDecimalString(value string) bool {
+	value = strings.TrimPrefix(value, "-")
+	digits := 0
+	dot := false
+	for _, char := range value {
+		switch {
+		case IsNumberic(char):
+			digits++
+		case char == '.' && !dot:
+			dot = true
+		default:
+			return false
+		}
+	}
+	return digits > 0
+}
+
+func rateDateKey(date DateTime) string {
+	if date.RawTime().IsZero() {
+		return ""
+	}
+	return date.Format("YYYY-MM-DD")
+}
+
+// StaticRateProvider keeps rates in memory, grouped by date. A lookup for a
+// date without rates uses the closest earlier date, so weekend dates resolve
+// to Friday's rates.
+type StaticRateProvider struct {
+	mutex sync.RWMutex
+	rates map[string]map[string]*BigNumber
+	dates []string
+}
+
+func NewStaticRateProvider() *StaticRateProvider {
+	return &StaticRateProvider{
+		rates: make(map[string]map[string]*BigNumber),
+	}
+}
+
+// SetRate records that one unit of base buys `rate` units of quote on date.
+// The rate must be a positive decimal.
+func (p *StaticRateProvider) SetRate(date DateTime, base, quote string, rate string) error {
+	rate = strings.TrimSpace(rate)
+	if !isDecimalString(rate) || strings.HasPrefix(rate, "-") || strings.Trim(rate, "0.") == "" {
+		return fmt.Errorf("%w: rate %q for %s/%s", ErrInvalidAmount, rate, base, quote)
+	}
+	key := rateDateKey(date)
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if _, ok := p.rates[key]; !ok {
+		p.rates[key] = make(map[string]*BigNumber)
+		p.dates = append(p.dates, key)
+		sort.Strings(p.dates)
+	}
+	p.rates[key][strings.ToUpper(base)+"/"+strings.ToUpper(quote)] = NewBigNumber(rate)
+	return nil
+}
+
+func (p *StaticRateProvider) Rate(base, quote string, date DateTime) (*BigNumber, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	pair := strings.ToUpper(base) + "/" + strings.ToUpper(quote)
+	key := rateDateKey(date)
+	// dates are sorted and "" (undated rates) sorts first
+	for i := len(p.dates) - 1; i >= 0; i-- {
+		if key != "" && p.dates[i] > key {
+			continue
+		}
+		if rate, ok := p.rates[p.dates[i]][pair]; ok {
+			return rate, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s on %q", ErrRateNotFound, pair, key)
+}
+
+type rateTable struct {
+	Base  string                 `json:"base"`
+	Date  string                 `json:"date"`
+	Rates map[string]json.Number `json:"rates"`
+}
+
+func parseRateDate(value string) (DateTime, error) {
+	if value == "" {
+		return DateTime{}, nil
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return DateTime{}, fmt.Errorf("invalid rate date %q: %w", value, err)
+	}
+	return From(date), nil
+}
+
+// LoadJSON reads rate tables shaped like
+//
+//	{"base": "USD", "date": "2024-01-02", "rates": {"EUR": "0.91", "JPY": 141.5}}
+//
+// either as a single object or as an array of them. The date is optional.
+func (p *StaticRateProvider) LoadJSON(reader io.Reader) error {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+	tables := []rateTable{}
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "[") {
+		err = decoder.Decode(&tables)
+	} else {
+		table := rateTable{}
+		err = decoder.Decode(&table)
+		tables = append(tables, table)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid rate file: %w", err)
+	}
+	for _, table := range tables {
+		date, err := parseRateDate(table.Date)
+		if err != nil {
+			return err
+		}
+		for quote, rate := range table.Rates {
+			if err := p.SetRate(date, table.Base, quote, rate.String()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// LoadCSV reads rows of "date,base,quote,rate". A header row starting with
+// "date" is skipped and the date column may be empty.
+func (p *StaticRateProvider) LoadCSV(reader io.Reader) error {
+	rows, err := csv.NewReader(reader).ReadAll()
+	if err != nil {
+		return fmt.Errorf("invalid rate file: %w", err)
+	}
+	for i, row := range rows {
+		if i == 0 && len(row) > 0 && strings.EqualFold(strings.TrimSpace(row[0]), "date") {
+			continue
+		}
+		if len(row) != 4 {
+			return fmt.Errorf("invalid rate file: line %d has %d columns, expected 4", i+1, len(row))
+		}
+		date, err := parseRateDate(strings.TrimSpace(row[0]))
+		if err != nil {
+			return err
+		}
+		if err := p.SetRate(date, strings.TrimSpace(row[1]), strings.TrimSpace(row[2]), row[3]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewFileRateProvider loads a ".json" or ".csv" rate file into a StaticRateProvider.
+func NewFileRateProvider(filename string) (*StaticRateProvider, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	provider := NewStaticRateProvider()
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		err = provider.LoadJSON(file)
+	case ".csv":
+		err = provider.LoadCSV(file)
+	default:
+		err = fmt.Errorf("unsupported rate file %q, expected .json or .csv", filename)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return provider, nil
+}
+
+// CurrencyConverter converts amounts between currencies with BigNumber
+// arithmetic. A missing pair is derived from its inverse or triangulated
+// through Base, e.g. EUR to JPY through USD.
+type CurrencyConverter struct {
+	Provider RateProvider
+	Base     string
+	Rounding RoundingMode
+	// Precision is the number of decimals kept when a rate has to be divided.
+	Precision int
+}
+
+func NewCurrencyConverter(provider RateProvider, base string) *CurrencyConverter {
+	return &CurrencyConverter{
+		Provider:  provider,
+		Base:      strings.ToUpper(base),
+		Rounding:  RoundHalfEven,
+		Precision: 18,
+	}
+}
+
+func (c *CurrencyConverter) divide(a, b *BigNumber) *BigNumber {
+	dividend := NewBigNumber(a.String())
+	dividend.format.maxDecimal = c.Precision
+	return dividend.Divide(b)
+}
+
+// Rate returns how many units of `to` one unit of `from` buys on date.
+func (c *CurrencyConverter) Rate(from, to string, date DateTime) (*BigNumber, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if from == to {
+		return NewBigNumber("1"), nil
+	}
+	if rate, err := c.Provider.Rate(from, to, date); err == nil {
+		return rate, nil
+	} else if !errors.Is(err, ErrRateNotFound) {
+		return nil, err
+	}
+	if rate, err := c.Provider.Rate(to, from, date); err == nil {
+		return c.divide(NewBigNumber("1"), rate), nil
+	} else if !errors.Is(err, ErrRateNotFound) {
+		return nil, err
+	}
+	if c.Base == "" || from == c.Base || to == c.Base {
+		return nil, fmt.Errorf("%w: %s/%s", ErrRateNotFound, from, to)
+	}
+	fromBase, err := c.Rate(c.Base, from, date)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s/%s", ErrRateNotFound, from, to)
+	}
+	toBase, err := c.Rate(c.Base, to, date)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s/%s", ErrRateNotFound, from, to)
+	}
+	return c.divide(toBase, fromBase), nil
+}
+
+// Convert converts amount from one currency to another and rounds the
+// result to the minor unit of the target currency.
+func (c *CurrencyConverter) Convert(amount string, from, to string, date DateTime) (*BigNumber, error) {
+	amount = strings.TrimSpace(amount)
+	if !isDecimalString(amount) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidAmount, amount)
+	}
+	rate, err := c.Rate(from, to, date)
+	if err != nil {
+		return nil, err
+	}
+	return NewBigNumber(amount).Multiply(rate).Round(CurrencyMinorUnits(to), c.Rounding), nil
+}
@@ -0,0 +1,99 @@
+package utils_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	utils "github.com/jingyuexing/go-utils"
+)
+
+func rateDate(value string) utils.DateTime {
+	date, _ := time.Parse("2006-01-02", value)
+	return utils.From(date)
+}
+
+func TestCurrencyMinorUnits(t *testing.T) {
+	cases := map[string]int{"USD": 2, "jpy": 0, "KWD": 3, "CLF": 4, "XXX": 2}
+	for code, expected := range cases {
+		if got := utils.CurrencyMinorUnits(code); got != expected {
+			t.Error(fmt.Sprintf("Expect: %d, but got %d (%s)", expected, got, code))
+		}
+	}
+}
+
+func TestCurrencyConverter(t *testing.T) {
+	provider, err := utils.NewFileRateProvider("testdata/rates.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	converter := utils.NewCurrencyConverter(provider, "USD")
+	cases := []struct {
+		amount, from, to string
+		date             utils.DateTime
+		expected         string
+	}{
+		{"100", "USD", "EUR", rateDate("2024-01-02"), "91.20"},
+		{"100", "USD", "EUR", rateDate("2024-01-03"), "91.50"},
+		// the weekend uses the latest earlier rates
+		{"100", "USD", "EUR", rateDate("2024-01-06"), "91.50"},
+		{"100", "usd", "jpy", utils.DateTime{}, "14310"},
+		{"1000", "USD", "KWD", rateDate("2024-01-02"), "307.500"},
+		// inverse of USD/EUR
+		{"91.20", "EUR", "USD", rateDate("2024-01-02"), "100.00"},
+		// triangulated through USD: 100 * 141.95 / 0.9120
+		{"100", "EUR", "JPY", rateDate("2024-01-02"), "15565"},
+		{"-50", "GBP", "EUR", rateDate("2024-01-02"), "-58.02"},
+		{"12.5", "EUR", "EUR", utils.DateTime{}, "12.50"},
+	}
+	for _, c := range cases {
+		got, err := converter.Convert(c.amount, c.from, c.to, c.date)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if got.String() != c.expected {
+			t.Error(fmt.Sprintf("Expect: %s, but got %s (%s %s -> %s)", c.expected, got.String(), c.amount, c.from, c.to))
+		}
+	}
+
+	if _, err := converter.Convert("100", "USD", "EUR", rateDate("2023-12-31")); !errors.Is(err, utils.ErrRateNotFound) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrRateNotFound, err))
+	}
+	if _, err := converter.Convert("1O0", "USD", "EUR", utils.DateTime{}); !errors.Is(err, utils.ErrInvalidAmount) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrInvalidAmount, err))
+	}
+}
+
+func TestRateProviderCSV(t *testing.T) {
+	provider, err := utils.NewFileRateProvider("testdata/rates.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	converter := utils.NewCurrencyConverter(provider, "EUR")
+	got, err := converter.Convert("10", "USD", "CNY", rateDate("2024-01-02"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 10 * 7.8120 / 1.0965
+	if got.String() != "71.24" {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s", "71.24", got.String()))
+	}
+	// undated rates apply to every date
+	got, err = converter.Convert("1", "EUR", "CHF", rateDate("2030-01-01"))
+	if err != nil || got.String() != "0.93" {
+		t.Error(fmt.Sprintf("Expect: %s, but got %v (%v)", "0.93", got, err))
+	}
+
+	static := utils.NewStaticRateProvider()
+	if err := static.LoadCSV(strings.NewReader("2024-01-02,USD,EUR,abc\n")); !errors.Is(err, utils.ErrInvalidAmount) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrInvalidAmount, err))
+	}
+	for _, rate := range []string{"-1.2", "0", "-0.0"} {
+		if err := static.SetRate(utils.DateTime{}, "USD", "EUR", rate); !errors.Is(err, utils.ErrInvalidAmount) {
+			t.Error(fmt.Sprintf("Expect: %v for rate %s, but got %v", utils.ErrInvalidAmount, rate, err))
+		}
+	}
+}
@@ -27,6 +27,13 @@ const (
 	FormatMillisecond FormatTemplate = "ms"
 	FormatWeek        FormatTemplate = "W"
 	FormatShortWeek   FormatTemplate = "WW"
+
+	FormatQuarter         FormatTemplate = "Q"
+	FormatWeekOfYear      FormatTemplate = "ww"
+	FormatShortWeekOfYear FormatTemplate = "w"
+	FormatFiscalYear      FormatTemplate = "FYYYY"
+	FormatFiscalQuarter   FormatTemplate = "FQ"
+	FormatFiscalPeriod    FormatTemplate = "FP"
 )
 
 const (
@@ -56,27 +63,33 @@ func DateTimeFormat(date time.Time, format string) string {
 		FormatMillisecond: date.Nanosecond() / int(time.Millisecond),
 		FormatWeek:        int(date.Weekday()),
 		FormatShortWeek:   int(date.Weekday()),
+
+		FormatWeekOfYear: WeekNumber(date, WeekISO),
 	}
 
-	for _, key := range []FormatTemplate{FormatYear, FormatMonth, FormatShortYear, FormatShortMonth, FormatDay, FormatUpperDay, FormatShortDay, FormatHour, FormatShortHour, FormatSecond, FormatShortSecond, FormatMinute, FormatShortMinute, FormatMillisecond, FormatWeek, FormatShortWeek} {
-		formatted = strings.Replace(
-			formatted,
-			string(key),
-			strings.Join(
-				PadStart(
-					strings.Split(ToString(formatMap[key]), ""),
-					len(string(key)),
-					"0",
-				),
-				"",
-			),
-			1,
-		)
+	for _, key := range []FormatTemplate{FormatYear, FormatMonth, FormatShortYear, FormatShortMonth, FormatDay, FormatUpperDay, FormatShortDay, FormatHour, FormatShortHour, FormatSecond, FormatShortSecond, FormatMinute, FormatShortMinute, FormatMillisecond, FormatWeek, FormatShortWeek, FormatWeekOfYear} {
+		formatted = replaceFormatToken(formatted, key, formatMap[key], len(string(key)))
 	}
 
 	return formatted
 }
 
+func replaceFormatToken(format string, key FormatTemplate, value int, width int) string {
+	return strings.Replace(
+		format,
+		string(key),
+		strings.Join(
+			PadStart(
+				strings.Split(ToString(value), ""),
+				width,
+				"0",
+			),
+			"",
+		),
+		1,
+	)
+}
+
 type FormatCallback func(int) string
 
 type DateTime struct {
@@ -95,6 +108,8 @@ type DateTime struct {
 	TimeFormat   string
 	monthFormat  FormatCallback
 	weekFormat   FormatCallback
+	fiscal       FiscalCalendar
+	weekSystem   WeekSystem
 }
 
 func NewDateTime() DateTime {
@@ -125,6 +140,8 @@ func (dt DateTime) SetDateTime(year, month, day, hour, minute, second, nanosecon
         time:       newTime,
         DateFormat: dt.DateFormat,
         TimeFormat: dt.TimeFormat,
+        fiscal:     dt.fiscal,
+        weekSystem: dt.weekSystem,
         Year:       newTime.Year(),
         Month:      int(newTime.Month()),
         Day:        newTime.Day(),
@@ -222,7 +239,26 @@ func (dt DateTime) SetNanosecond(nanoseconds int) DateTime {
 	return dt.SetSecond(dt.Second, nanoseconds)
 }
 
+// Format renders the date like DateTimeFormat, and additionally resolves the
+// fiscal tokens, the quarter and the week of year tokens using the
+// configured fiscal calendar and week system. The one letter Q and w tokens
+// are only known here, DateTimeFormat leaves them as text.
 func (dt DateTime) Format(format string) string {
+	tokens := []struct {
+		key   FormatTemplate
+		value int
+		width int
+	}{
+		{FormatFiscalYear, dt.FiscalYear(), 4},
+		{FormatFiscalQuarter, dt.FiscalQuarter(), 1},
+		{FormatFiscalPeriod, dt.FiscalPeriod(), 2},
+		{FormatQuarter, Quarter(dt.time), 1},
+		{FormatWeekOfYear, dt.WeekOfYear(), 2},
+		{FormatShortWeekOfYear, dt.WeekOfYear(), 1},
+	}
+	for _, token := range tokens {
+		format = replaceFormatToken(format, token.key, token.value, token.width)
+	}
 	return DateTimeFormat(dt.time, format)
 }
 
@@ -267,6 +303,8 @@ func (dt DateTime) SetTime(sec int64, ns int64) DateTime {
 		TimeFormat:  dt.TimeFormat,
 		monthFormat: dt.monthFormat,
 		weekFormat:  dt.weekFormat,
+		fiscal:      dt.fiscal,
+		weekSystem:  dt.weekSystem,
 	}
 	d.Year = d.time.Year()
 	d.Month = int(d.time.Month())
@@ -336,9 +374,10 @@ func (d DateTime) Progress() float32 {
     return float32(d.time.YearDay()) / float32(d.CountDays())
 }
 
+// WeekOfYear returns the week number under the week system set by
+// WithWeekSystem, ISO 8601 by default.
 func (d DateTime) WeekOfYear() int {
-	_, week := d.time.ISOWeek()
-	return week
+	return WeekNumber(d.time, d.weekSystem)
 }
 
 func (d DateTime) Add(num int, unit AddUnits) DateTime {
@@ -0,0 +1,496 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrDecode = errors.New("decode failed")
+
+// FieldError is a decoding error of the field at Path.
+type FieldError struct {
+	Path string
+	Err  error
+}
+
+func (e FieldError) Error() string {
+	if e.Path == "" {
+		return e.Err.Error()
+	}
+	return e.Path + ": " + e.Err.Error()
+}
+
+// DecodeError collects every field that could not be decoded.
+type DecodeError struct {
+	Errors []FieldError
+}
+
+func (e *DecodeError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("%d decoding error(s): %s", len(e.Errors), strings.Join(messages, "; "))
+}
+
+func (e *DecodeError) Is(target error) bool {
+	return target == ErrDecode
+}
+
+type DecodeOptions struct {
+	// TagName is the struct tag holding field names, "json" by default.
+	TagName string
+	// Strict turns off the conversions between strings, numbers and bools.
+	Strict bool
+}
+
+func WithDecodeTag(tag string) DataOption[DecodeOptions] {
+	return func(o *DecodeOptions) {
+		o.TagName = tag
+	}
+}
+
+func WithStrictDecode() DataOption[DecodeOptions] {
+	return func(o *DecodeOptions) {
+		o.Strict = true
+	}
+}
+
+var (
+	durationType  = reflect.TypeOf(time.Duration(0))
+	timeType      = reflect.TypeOf(time.Time{})
+	dateTimeType  = reflect.TypeOf(DateTime{})
+	bigNumberType = reflect.TypeOf(BigNumber{})
+)
+
+type decoder struct {
+	options DecodeOptions
+	errors  []FieldError
+}
+
+func (d *decoder) fail(path string, format string, args ...any) {
+	d.errors = append(d.errors, FieldError{Path: path, Err: fmt.Errorf(format, args...)})
+}
+
+func joinDecodePath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// decodeField is a struct field with its name in the source map.
+type decodeField struct {
+	name   string
+	value  reflect.Value
+	remain bool
+}
+
+// fields lists the fields of a struct, with embedded structs without a
+// tag name flattened into it.
+func (d *decoder) fields(value reflect.Value) []decodeField {
+	result := []decodeField{}
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		tag := field.Tag.Get(d.options.TagName)
+		if tag == "-" {
+			continue
+		}
+		name, flags, _ := strings.Cut(tag, ",")
+		fieldValue := value.Field(i)
+		if field.Anonymous && name == "" {
+			embedded := fieldValue
+			if embedded.Kind() == reflect.Pointer && embedded.Type().Elem().Kind() == reflect.Struct {
+				if embedded.IsNil() && embedded.CanSet() {
+					embedded.Set(reflect.New(embedded.Type().Elem()))
+				}
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				result = append(result, d.fields(embedded)...)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		result = append(result, decodeField{name: name, value: fieldValue, remain: strings.Contains(","+flags+",", ",remain,")})
+	}
+	return result
+}
+
+// sourceKey finds the key of field in source: the tag or field name, then
+// its lower camel or snake case form, then ignoring case.
+func sourceKey(source map[string]reflect.Value, name string) (string, bool) {
+	for _, candidate := range []string{name, ToLowerCamelCase(name), SnakeCase(name)} {
+		if _, ok := source[candidate]; ok {
+			return candidate, true
+		}
+	}
+	keys := make([]string, 0, len(source))
+	for key := range source {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if strings.EqualFold(key, name) || strings.EqualFold(key, SnakeCase(name)) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+func (d *decoder) decodeStruct(path string, target reflect.Value, source reflect.Value) {
+	if source.Kind() == reflect.Struct && source.Type() == target.Type() {
+		target.Set(source)
+		return
+	}
+	if source.Kind() != reflect.Map || source.Type().Key().Kind() != reflect.String {
+		d.fail(path, "can not decode %s into %s", source.Type(), target.Type())
+		return
+	}
+	entries := map[string]reflect.Value{}
+	iter := source.MapRange()
+	for iter.Next() {
+		entries[iter.Key().String()] = iter.Value()
+	}
+	used := map[string]bool{}
+	var remain *decodeField
+	for _, field := range d.fields(target) {
+		if field.remain {
+			field := field
+			remain = &field
+			continue
+		}
+		key, ok := sourceKey(entries, field.name)
+		if !ok {
+			continue
+		}
+		used[key] = true
+		d.decode(joinDecodePath(path, key), field.value, entries[key])
+	}
+	if remain == nil {
+		return
+	}
+	rest := map[string]any{}
+	for key, value := range entries {
+		if !used[key] {
+			rest[key] = value.Interface()
+		}
+	}
+	d.decode(joinDecodePath(path, remain.name), remain.value, reflect.ValueOf(rest))
+}
+
+func (d *decoder) decodeSlice(path string, target reflect.Value, source reflect.Value) {
+	if source.Kind() == reflect.String && target.Type().Elem().Kind() == reflect.Uint8 {
+		target.SetBytes([]byte(source.String()))
+		return
+	}
+	if source.Kind() != reflect.Slice && source.Kind() != reflect.Array {
+		if d.options.Strict {
+			d.fail(path, "can not decode %s into %s", source.Type(), target.Type())
+			return
+		}
+		// a single value becomes a slice of one item
+		single := reflect.MakeSlice(reflect.TypeOf([]any{}), 1, 1)
+		single.Index(0).Set(source)
+		source = single
+	}
+	length := source.Len()
+	if target.Kind() == reflect.Array {
+		if length > target.Len() {
+			d.fail(path, "%d items do not fit in %s", length, target.Type())
+			return
+		}
+	} else {
+		target.Set(reflect.MakeSlice(target.Type(), length, length))
+	}
+	for i := 0; i < length; i++ {
+		d.decode(joinDecodePath(path, strconv.Itoa(i)), target.Index(i), source.Index(i))
+	}
+}
+
+func (d *decoder) decodeMap(path string, target reflect.Value, source reflect.Value) {
+	if source.Kind() != reflect.Map {
+		d.fail(path, "can not decode %s into %s", source.Type(), target.Type())
+		return
+	}
+	if target.IsNil() {
+		target.Set(reflect.MakeMapWithSize(target.Type(), source.Len()))
+	}
+	iter := source.MapRange()
+	for iter.Next() {
+		itemPath := joinDecodePath(path, fmt.Sprint(iter.Key().Interface()))
+		key := reflect.New(target.Type().Key()).Elem()
+		d.decode(itemPath, key, iter.Key())
+		item := reflect.New(target.Type().Elem()).Elem()
+		d.decode(itemPath, item, iter.Value())
+		target.SetMapIndex(key, item)
+	}
+}
+
+// decodeSpecial converts durations, dates and big numbers.
+func (d *decoder) decodeSpecial(path string, target reflect.Value, source reflect.Value) bool {
+	switch target.Type() {
+	case durationType:
+		if source.Kind() != reflect.String {
+			return false
+		}
+		duration, err := time.ParseDuration(source.String())
+		if err != nil {
+			if duration, err = ParseHumanDuration(source.String()); err != nil {
+				d.fail(path, "%v", err)
+				return true
+			}
+		}
+		target.SetInt(int64(duration))
+		return true
+	case timeType, dateTimeType:
+		var date time.Time
+		switch {
+		case source.Type() == timeType:
+			date = source.Interface().(time.Time)
+		case source.Type() == dateTimeType:
+			date = *source.Interface().(DateTime).RawTime()
+		case source.Kind() == reflect.String:
+			var err error
+			for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02"} {
+				if date, err = time.Parse(layout, source.String()); err == nil {
+					break
+				}
+			}
+			if err != nil {
+				d.fail(path, "invalid date %q", source.String())
+				return true
+			}
+		default:
+			seconds, ok := pathNumber(source.Interface())
+			if !ok {
+				d.fail(path, "can not decode %s into %s", source.Type(), target.Type())
+				return true
+			}
+			date = time.Unix(int64(seconds), 0).UTC()
+		}
+		if target.Type() == timeType {
+			target.Set(reflect.ValueOf(date))
+		} else {
+			target.Set(reflect.ValueOf(From(date)))
+		}
+		return true
+	case bigNumberType:
+		number, ok := templateDecimal(source.Interface())
+		if !ok {
+			d.fail(path, "invalid number %v", source.Interface())
+			return true
+		}
+		target.Set(reflect.ValueOf(*number))
+		return true
+	}
+	return false
+}
+
+func (d *decoder) decodeScalar(path string, target reflect.Value, source reflect.Value) {
+	if source.Type().AssignableTo(target.Type()) {
+		target.Set(source)
+		return
+	}
+	// named types of the same kind, such as a bool into type Flag bool
+	if source.Kind() == target.Kind() && source.Type().ConvertibleTo(target.Type()) {
+		target.Set(source.Convert(target.Type()))
+		return
+	}
+	mismatch := func() {
+		d.fail(path, "can not decode %s into %s", source.Type(), target.Type())
+	}
+	number, isNumber := pathNumber(source.Interface())
+	isText := source.Kind() == reflect.String
+	if d.options.Strict && (isText != (target.Kind() == reflect.String) || source.Kind() == reflect.Bool) {
+		mismatch()
+		return
+	}
+	text := ""
+	if isText {
+		text = strings.TrimSpace(source.String())
+	}
+	switch target.Kind() {
+	case reflect.String:
+		switch {
+		case isText:
+			target.SetString(source.String())
+		case isNumber || source.Kind() == reflect.Bool:
+			target.SetString(fmt.Sprint(source.Interface()))
+		case source.Kind() == reflect.Slice && source.Type().Elem().Kind() == reflect.Uint8:
+			target.SetString(string(source.Bytes()))
+		default:
+			mismatch()
+		}
+	case reflect.Bool:
+		switch {
+		case source.Kind() == reflect.Bool:
+			target.SetBool(source.Bool())
+		case isNumber:
+			target.SetBool(number != 0)
+		case isText:
+			value, err := strconv.ParseBool(text)
+			if err != nil && text != "" {
+				d.fail(path, "invalid bool %q", text)
+				return
+			}
+			target.SetBool(value)
+		default:
+			mismatch()
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch {
+		case isText:
+			value, err := strconv.ParseInt(text, 10, target.Type().Bits())
+			if err != nil {
+				d.fail(path, "invalid integer %q", text)
+				return
+			}
+			target.SetInt(value)
+		case source.Kind() == reflect.Bool:
+			target.SetInt(map[bool]int64{true: 1}[source.Bool()])
+		case isNumber:
+			integer := int64(number)
+			if source.CanInt() {
+				integer = source.Int()
+			}
+			if number != math.Trunc(number) || target.OverflowInt(integer) {
+				d.fail(path, "%v does not fit in %s", source.Interface(), target.Type())
+				return
+			}
+			target.SetInt(integer)
+		default:
+			mismatch()
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		switch {
+		case isText:
+			value, err := strconv.ParseUint(text, 10, target.Type().Bits())
+			if err != nil {
+				d.fail(path, "invalid unsigned integer %q", text)
+				return
+			}
+			target.SetUint(value)
+		case source.Kind() == reflect.Bool:
+			target.SetUint(map[bool]uint64{true: 1}[source.Bool()])
+		case isNumber:
+			integer := uint64(number)
+			if source.CanUint() {
+				integer = source.Uint()
+			}
+			if number < 0 || number != math.Trunc(number) || target.OverflowUint(integer) {
+				d.fail(path, "%v does not fit in %s", source.Interface(), target.Type())
+				return
+			}
+			target.SetUint(integer)
+		default:
+			mismatch()
+		}
+	case reflect.Float32, reflect.Float64:
+		switch {
+		case isText:
+			value, err := strconv.ParseFloat(text, target.Type().Bits())
+			if err != nil {
+				d.fail(path, "invalid number %q", text)
+				return
+			}
+			target.SetFloat(value)
+		case source.Kind() == reflect.Bool:
+			target.SetFloat(map[bool]float64{true: 1}[source.Bool()])
+		case isNumber:
+			target.SetFloat(number)
+		default:
+			mismatch()
+		}
+	default:
+		mismatch()
+	}
+}
+
+func (d *decoder) decode(path string, target reflect.Value, source reflect.Value) {
+	for source.Kind() == reflect.Interface || source.Kind() == reflect.Pointer {
+		if source.IsNil() {
+			return
+		}
+		// keep a pointer the target takes but not its value, such as a
+		// *bytes.Buffer for an io.Reader
+		if source.Kind() == reflect.Pointer && (source.Type() == target.Type() ||
+			source.Type().AssignableTo(target.Type()) && !source.Type().Elem().AssignableTo(target.Type())) {
+			break
+		}
+		source = source.Elem()
+	}
+	if !source.IsValid() {
+		return
+	}
+	switch target.Kind() {
+	case reflect.Pointer:
+		if source.Type() == target.Type() {
+			target.Set(source)
+			return
+		}
+		if target.IsNil() {
+			target.Set(reflect.New(target.Type().Elem()))
+		}
+		d.decode(path, target.Elem(), source)
+		return
+	case reflect.Interface:
+		if source.Type().AssignableTo(target.Type()) {
+			target.Set(source)
+		} else {
+			d.fail(path, "%s does not implement %s", source.Type(), target.Type())
+		}
+		return
+	}
+	if d.decodeSpecial(path, target, source) {
+		return
+	}
+	switch target.Kind() {
+	case reflect.Struct:
+		d.decodeStruct(path, target, source)
+	case reflect.Slice, reflect.Array:
+		d.decodeSlice(path, target, source)
+	case reflect.Map:
+		d.decodeMap(path, target, source)
+	default:
+		d.decodeScalar(path, target, source)
+	}
+}
+
+// Decode fills target, a pointer, from source, usually maps decoded from
+// JSON or YAML. Struct fields are found by their tag, their name or its
+// lower camel or snake case form, and values are converted between
+// strings, numbers and bools unless WithStrictDecode is given. Every
+// failing field is reported in a *DecodeError.
+func Decode(source any, target any, opts ...DataOption[DecodeOptions]) error {
+	options := DecodeOptions{TagName: "json"}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Pointer || value.IsNil() {
+		return fmt.Errorf("%w: target must be a non nil pointer, got %T", ErrDecode, target)
+	}
+	d := &decoder{options: options}
+	d.decode("", value.Elem(), reflect.ValueOf(source))
+	if len(d.errors) > 0 {
+		return &DecodeError{Errors: d.errors}
+	}
+	return nil
+}
+
+// Map2Struct fills the struct bindingTarget points to from source, see Decode.
+func Map2Struct(source map[string]any, bindingTarget any, opts ...DataOption[DecodeOptions]) error {
+	return Decode(source, bindingTarget, opts...)
+}
@@ -0,0 +1,143 @@
+package utils_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	utils "github.com/jingyuexing/go-utils"
+)
+
+type decodeBase struct {
+	ID      int `json:"id"`
+	Created utils.DateTime
+}
+
+type decodePort struct {
+	Number   uint16 `json:"number"`
+	Protocol string
+}
+
+type decodeServer struct {
+	decodeBase
+	Host    string        `json:"host"`
+	Ports   []decodePort  `json:"ports"`
+	Timeout time.Duration `json:"timeout"`
+	Weights map[string]float64
+	Price   utils.BigNumber `json:"price"`
+	Enabled bool
+	Backup  *decodeServer
+	Tags    []string
+	Extra   map[string]any `json:",remain"`
+	Secret  string         `json:"-"`
+}
+
+func TestDecode(t *testing.T) {
+	source := map[string]any{
+		"id":      "42",
+		"created": "2024-03-01 10:20:30",
+		"host":    "localhost",
+		"ports":   []any{map[string]any{"number": 80.0, "protocol": "tcp"}, map[string]any{"number": "443"}},
+		"timeout": "1m30s",
+		"weights": map[string]any{"a": "0.5", "b": 2},
+		"price":   "19.99",
+		"enabled": "true",
+		"backup":  map[string]any{"host": "standby", "timeout": 1e9},
+		"tags":    "single",
+		"region":  "eu",
+		"Secret":  "hidden",
+	}
+	server := decodeServer{}
+	if err := utils.Decode(source, &server); err != nil {
+		t.Fatal(err)
+	}
+	expected := "42 2024-03-01 10:20:30 localhost [{80 tcp} {443 }] 1m30s map[a:0.5 b:2] 19.99 true standby 1s [single] map[Secret:hidden region:eu]"
+	got := fmt.Sprintf("%d %s %s %v %s %v %s %t %s %s %v %v", server.ID, server.Created.RawTime().Format("2006-01-02 15:04:05"),
+		server.Host, server.Ports, server.Timeout, server.Weights, server.Price.String(), server.Enabled,
+		server.Backup.Host, server.Backup.Timeout, server.Tags, server.Extra)
+	if got != expected {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s", expected, got))
+	}
+	if server.Secret != "" {
+		t.Error(fmt.Sprintf("Expect: ignored field to stay empty, but got %s", server.Secret))
+	}
+	dash := struct {
+		Dash string `json:"-,"`
+		Skip string `json:"-"`
+	}{}
+	if err := utils.Decode(map[string]any{"-": "d", "Skip": "s"}, &dash); err != nil || dash.Dash != "d" || dash.Skip != "" {
+		t.Error(fmt.Sprintf("Expect: {d }, but got %v (%v)", dash, err))
+	}
+
+	type human struct {
+		Every time.Duration
+		Count int64
+	}
+	value := human{}
+	if err := utils.Decode(map[string]any{"every": "1d 2h", "count": 3.0}, &value); err != nil || value.Every != 26*time.Hour || value.Count != 3 {
+		t.Error(fmt.Sprintf("Expect: {26h0m0s 3}, but got %v (%v)", value, err))
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	server := decodeServer{}
+	err := utils.Decode(map[string]any{
+		"id":    1.5,
+		"ports": []any{map[string]any{"number": 70000}, map[string]any{"number": "x"}},
+		"backup": map[string]any{
+			"enabled": "maybe",
+		},
+		"host": "still decoded",
+	}, &server)
+	var decodeErr *utils.DecodeError
+	if !errors.As(err, &decodeErr) || !errors.Is(err, utils.ErrDecode) {
+		t.Fatal(fmt.Sprintf("Expect: %v, but got %v", utils.ErrDecode, err))
+	}
+	paths := []string{}
+	for _, fieldErr := range decodeErr.Errors {
+		paths = append(paths, fieldErr.Path)
+	}
+	expected := "backup.enabled id ports.0.number ports.1.number"
+	sort.Strings(paths)
+	if strings.Join(paths, " ") != expected {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s", expected, paths))
+	}
+	if server.Host != "still decoded" {
+		t.Error(fmt.Sprintf("Expect: still decoded, but got %s", server.Host))
+	}
+
+	strict := struct{ Age int }{}
+	if err := utils.Decode(map[string]any{"age": "10"}, &strict, utils.WithStrictDecode()); !errors.Is(err, utils.ErrDecode) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrDecode, err))
+	}
+	type flag bool
+	named := struct {
+		Flag  flag
+		Level uint8
+	}{}
+	if err := utils.Decode(map[string]any{"flag": true, "level": uint8(3)}, &named, utils.WithStrictDecode()); err != nil || !bool(named.Flag) || named.Level != 3 {
+		t.Error(fmt.Sprintf("Expect: {true 3}, but got %v (%v)", named, err))
+	}
+	reader := struct{ Body io.Reader }{}
+	if err := utils.Decode(map[string]any{"body": bytes.NewBufferString("text")}, &reader); err != nil {
+		t.Error(err)
+	} else if body, _ := io.ReadAll(reader.Body); string(body) != "text" {
+		t.Error(fmt.Sprintf("Expect: text, but got %s", body))
+	}
+	if err := utils.Decode(map[string]any{}, strict); !errors.Is(err, utils.ErrDecode) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrDecode, err))
+	}
+
+	tagged := struct {
+		Name string `form:"user_name"`
+	}{}
+	utils.Map2Struct(map[string]any{"user_name": "ann"}, &tagged, utils.WithDecodeTag("form"))
+	if tagged.Name != "ann" {
+		t.Error(fmt.Sprintf("Expect: ann, but got %s", tagged.Name))
+	}
+}
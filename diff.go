@@ -0,0 +1,516 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidPatch = errors.New("invalid patch")
+	ErrPatchTest    = errors.New("patch test failed")
+)
+
+type ChangeType string
+
+const (
+	ChangeAdded   ChangeType = "added"
+	ChangeRemoved ChangeType = "removed"
+	ChangeChanged ChangeType = "changed"
+)
+
+// Change is a difference found by Diff. Path holds the map keys, field
+// names (as GetFieldName) and slice indexes leading to the value.
+type Change struct {
+	Type ChangeType
+	Path []string
+	From any
+	To   any
+
+	// sliceDepth is the length of the path to the outermost slice holding
+	// the change, -1 outside slices, and slice the new value of that slice.
+	// Merge patches replace such slices as a whole.
+	sliceDepth int
+	slice      any
+}
+
+// Pointer returns the path as an RFC 6901 JSON Pointer.
+func (c Change) Pointer() string {
+	return jsonPointer(c.Path)
+}
+
+func (c Change) String() string {
+	switch c.Type {
+	case ChangeAdded:
+		return fmt.Sprintf("added %s: %v", c.Pointer(), c.To)
+	case ChangeRemoved:
+		return fmt.Sprintf("removed %s: %v", c.Pointer(), c.From)
+	}
+	return fmt.Sprintf("changed %s: %v -> %v", c.Pointer(), c.From, c.To)
+}
+
+type Changes []Change
+
+type DiffOptions struct {
+	// Ignore holds field names, json names or map keys left out at any depth.
+	Ignore map[string]bool
+	// Tag is the struct tag marking ignored fields with "-", "diff" by default.
+	Tag string
+}
+
+func WithDiffIgnore(names ...string) DataOption[DiffOptions] {
+	return func(o *DiffOptions) {
+		for _, name := range names {
+			o.Ignore[name] = true
+		}
+	}
+}
+
+func WithDiffTag(tag string) DataOption[DiffOptions] {
+	return func(o *DiffOptions) {
+		o.Tag = tag
+	}
+}
+
+func jsonPointer(path []string) string {
+	var builder strings.Builder
+	for _, key := range path {
+		builder.WriteByte('/')
+		builder.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(key))
+	}
+	return builder.String()
+}
+
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%w: pointer %q must start with /", ErrInvalidPatch, pointer)
+	}
+	keys := strings.Split(pointer[1:], "/")
+	for i, key := range keys {
+		keys[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(key)
+	}
+	return keys, nil
+}
+
+// diffTree converts value to map[string]any, []any and leaves, the form
+// Diff compares and patches are written in.
+func diffTree(value reflect.Value, options DiffOptions) any {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if !value.IsValid() {
+		return nil
+	}
+	if value.Type().Implements(textMarshalerType) {
+		return value.Interface()
+	}
+	switch value.Kind() {
+	case reflect.Map:
+		object := map[string]any{}
+		iter := value.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key().Interface())
+			if !options.Ignore[key] {
+				object[key] = diffTree(iter.Value(), options)
+			}
+		}
+		return object
+	case reflect.Struct:
+		object := map[string]any{}
+		for _, field := range reflect.VisibleFields(value.Type()) {
+			if !field.IsExported() || field.Anonymous || field.Tag.Get("json") == "-" || field.Tag.Get(options.Tag) == "-" {
+				continue
+			}
+			name := GetFieldName(field)
+			if options.Ignore[name] || options.Ignore[field.Name] {
+				continue
+			}
+			if fieldValue, err := value.FieldByIndexErr(field.Index); err == nil {
+				object[name] = diffTree(fieldValue, options)
+			}
+		}
+		return object
+	case reflect.Slice, reflect.Array:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			return value.Interface()
+		}
+		list := make([]any, value.Len())
+		for i := range list {
+			list[i] = diffTree(value.Index(i), options)
+		}
+		return list
+	}
+	return value.Interface()
+}
+
+func diffEqual(a any, b any) bool {
+	if aNumber, ok := pathNumber(a); ok {
+		bNumber, ok := pathNumber(b)
+		return ok && aNumber == bNumber
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+type differ struct {
+	changes    Changes
+	sliceDepth int
+	slice      any
+}
+
+func (d *differ) add(change Change) {
+	change.Path = append([]string{}, change.Path...)
+	change.sliceDepth, change.slice = d.sliceDepth, d.slice
+	d.changes = append(d.changes, change)
+}
+
+func (d *differ) walk(path []string, a any, b any) {
+	switch aValue := a.(type) {
+	case map[string]any:
+		bValue, ok := b.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := []string{}
+		for key := range aValue {
+			keys = append(keys, key)
+		}
+		for key := range bValue {
+			if _, ok := aValue[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			aItem, inA := aValue[key]
+			bItem, inB := bValue[key]
+			child := append(path, key)
+			switch {
+			case !inB:
+				d.add(Change{Type: ChangeRemoved, Path: child, From: aItem})
+			case !inA:
+				d.add(Change{Type: ChangeAdded, Path: child, To: bItem})
+			default:
+				d.walk(child, aItem, bItem)
+			}
+		}
+		return
+	case []any:
+		bValue, ok := b.([]any)
+		if !ok {
+			break
+		}
+		if d.sliceDepth < 0 {
+			d.sliceDepth, d.slice = len(path), bValue
+			defer func() { d.sliceDepth, d.slice = -1, nil }()
+		}
+		common := min(len(aValue), len(bValue))
+		for i := 0; i < common; i++ {
+			d.walk(append(path, strconv.Itoa(i)), aValue[i], bValue[i])
+		}
+		// the tail is removed from the end so that the indexes stay valid
+		for i := len(aValue) - 1; i >= common; i-- {
+			d.add(Change{Type: ChangeRemoved, Path: append(path, strconv.Itoa(i)), From: aValue[i]})
+		}
+		for i := common; i < len(bValue); i++ {
+			d.add(Change{Type: ChangeAdded, Path: append(path, strconv.Itoa(i)), To: bValue[i]})
+		}
+		return
+	}
+	if !diffEqual(a, b) {
+		d.add(Change{Type: ChangeChanged, Path: path, From: a, To: b})
+	}
+}
+
+func newDiffOptions(opts []DataOption[DiffOptions]) DiffOptions {
+	options := DiffOptions{Ignore: map[string]bool{}, Tag: "diff"}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// Diff compares a and b through maps, structs, slices and pointers and
+// returns the changes that turn a into b, in path order.
+func Diff(a any, b any, opts ...DataOption[DiffOptions]) Changes {
+	options := newDiffOptions(opts)
+	d := &differ{changes: Changes{}, sliceDepth: -1}
+	d.walk([]string{}, diffTree(reflect.ValueOf(a), options), diffTree(reflect.ValueOf(b), options))
+	return d.changes
+}
+
+// PatchOperation is an RFC 6902 JSON Patch operation.
+type PatchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	From  string `json:"from,omitempty"`
+	Value any    `json:"value,omitempty"`
+}
+
+// MarshalJSON writes the value of add, replace and test even when it is
+// null or zero.
+func (o PatchOperation) MarshalJSON() ([]byte, error) {
+	type operation PatchOperation
+	if o.Op != "add" && o.Op != "replace" && o.Op != "test" {
+		return json.Marshal(operation(o))
+	}
+	return json.Marshal(struct {
+		operation
+		Value any `json:"value"`
+	}{operation(o), o.Value})
+}
+
+// JSONPatch renders the changes as an RFC 6902 JSON Patch.
+func (c Changes) JSONPatch() []PatchOperation {
+	operations := []PatchOperation{}
+	for _, change := range c {
+		operation := PatchOperation{Path: change.Pointer()}
+		switch change.Type {
+		case ChangeAdded:
+			operation.Op, operation.Value = "add", change.To
+		case ChangeRemoved:
+			operation.Op = "remove"
+		default:
+			operation.Op, operation.Value = "replace", change.To
+		}
+		operations = append(operations, operation)
+	}
+	return operations
+}
+
+// MergePatch renders the changes as an RFC 7386 Merge Patch: removed keys
+// are null and changed slices are replaced as a whole.
+func (c Changes) MergePatch() any {
+	var patch any = map[string]any{}
+	for _, change := range c {
+		path, value := change.Path, change.To
+		if change.sliceDepth >= 0 {
+			path, value = change.Path[:change.sliceDepth], change.slice
+		} else if change.Type == ChangeRemoved {
+			value = nil
+		}
+		if len(path) == 0 {
+			return value
+		}
+		object, ok := patch.(map[string]any)
+		for _, key := range path[:len(path)-1] {
+			next, isObject := object[key].(map[string]any)
+			if !isObject {
+				next = map[string]any{}
+				object[key] = next
+			}
+			object = next
+		}
+		if ok {
+			object[path[len(path)-1]] = value
+		}
+	}
+	return patch
+}
+
+// patchTarget applies changes to a copy of the value behind target and
+// stores it back only when every change succeeds.
+func patchTarget(target any, apply func(root reflect.Value) error) error {
+	root, err := nestedRoot(target)
+	if err != nil {
+		return err
+	}
+	copied := reflect.New(root.Type())
+	copied.Elem().Set(cloneValue(root))
+	if err := apply(copied.Elem()); err != nil {
+		return err
+	}
+	if root.CanSet() {
+		root.Set(copied.Elem())
+		return nil
+	}
+	// a map passed by value is refilled
+	for _, key := range root.MapKeys() {
+		root.SetMapIndex(key, reflect.Value{})
+	}
+	iter := copied.Elem().MapRange()
+	for iter.Next() {
+		root.SetMapIndex(iter.Key(), iter.Value())
+	}
+	return nil
+}
+
+func patchSet(root reflect.Value, keys []string, value any) error {
+	updated, err := setNestedValue(root, root.Type(), keys, reflect.ValueOf(value))
+	if err == nil {
+		root.Set(updated)
+	}
+	return err
+}
+
+func patchGet(root reflect.Value, keys []string) (any, bool) {
+	return lookupNested(root.Addr().Interface(), keys)
+}
+
+// patchAdd adds value at keys, inserting it into slices.
+func patchAdd(root reflect.Value, keys []string, value any) error {
+	if len(keys) == 0 {
+		return patchSet(root, keys, value)
+	}
+	parentKeys, last := keys[:len(keys)-1], keys[len(keys)-1]
+	parent, ok := patchGet(root, parentKeys)
+	if !ok {
+		return fmt.Errorf("%w: %s does not exist", ErrInvalidPatch, jsonPointer(parentKeys))
+	}
+	list := derefPathValue(reflect.ValueOf(parent))
+	if list.Kind() != reflect.Slice {
+		return patchSet(root, keys, value)
+	}
+	index := list.Len()
+	if last != "-" {
+		var err error
+		index, err = strconv.Atoi(last)
+		if err != nil || index < 0 || index > list.Len() {
+			return fmt.Errorf("%w: index %s out of range", ErrInvalidPatch, last)
+		}
+	}
+	item, err := convertNested(reflect.ValueOf(value), list.Type().Elem())
+	if err != nil {
+		return err
+	}
+	inserted := reflect.MakeSlice(list.Type(), 0, list.Len()+1)
+	inserted = reflect.AppendSlice(inserted, list.Slice(0, index))
+	inserted = reflect.Append(inserted, item)
+	inserted = reflect.AppendSlice(inserted, list.Slice(index, list.Len()))
+	return patchSet(root, parentKeys, inserted.Interface())
+}
+
+func patchRemove(root reflect.Value, keys []string) error {
+	if len(keys) == 0 {
+		root.Set(reflect.Zero(root.Type()))
+		return nil
+	}
+	updated, err := deleteNestedValue(root, keys)
+	if err == nil {
+		root.Set(updated)
+	}
+	return err
+}
+
+func applyOperation(root reflect.Value, operation PatchOperation, options DiffOptions) error {
+	keys, err := parseJSONPointer(operation.Path)
+	if err != nil {
+		return err
+	}
+	switch operation.Op {
+	case "add":
+		return patchAdd(root, keys, operation.Value)
+	case "remove":
+		return patchRemove(root, keys)
+	case "replace":
+		if _, ok := patchGet(root, keys); !ok {
+			return fmt.Errorf("%w: %s does not exist", ErrInvalidPatch, operation.Path)
+		}
+		return patchSet(root, keys, operation.Value)
+	case "move", "copy":
+		from, err := parseJSONPointer(operation.From)
+		if err != nil {
+			return err
+		}
+		value, ok := patchGet(root, from)
+		if !ok {
+			return fmt.Errorf("%w: %s does not exist", ErrInvalidPatch, operation.From)
+		}
+		value = cloneValue(reflect.ValueOf(value)).Interface()
+		if operation.Op == "move" {
+			if err := patchRemove(root, from); err != nil {
+				return err
+			}
+		}
+		return patchAdd(root, keys, value)
+	case "test":
+		value, ok := patchGet(root, keys)
+		if !ok || len(Diff(value, operation.Value, func(o *DiffOptions) { *o = options })) > 0 {
+			return fmt.Errorf("%w: %s", ErrPatchTest, operation.Path)
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: unknown op %q", ErrInvalidPatch, operation.Op)
+}
+
+// ApplyPatch applies an RFC 6902 JSON Patch to target, a pointer or a map.
+// The patch is atomic: target is left unchanged when an operation fails.
+func ApplyPatch(target any, patch []PatchOperation) error {
+	options := newDiffOptions(nil)
+	return patchTarget(target, func(root reflect.Value) error {
+		for i, operation := range patch {
+			if err := applyOperation(root, operation, options); err != nil {
+				return fmt.Errorf("operation %d: %w", i, err)
+			}
+		}
+		return nil
+	})
+}
+
+// withoutNulls drops the null members of a merge patch value that is not
+// merged into an object.
+func withoutNulls(value any) any {
+	object, ok := value.(map[string]any)
+	if !ok {
+		return value
+	}
+	result := map[string]any{}
+	for key, item := range object {
+		if item != nil {
+			result[key] = withoutNulls(item)
+		}
+	}
+	return result
+}
+
+func applyMergePatch(root reflect.Value, path []string, patch map[string]any) error {
+	keys := make([]string, 0, len(patch))
+	for key := range patch {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		child := append(append([]string{}, path...), key)
+		value := patch[key]
+		existing, exists := patchGet(root, child)
+		if value == nil {
+			if exists {
+				if err := patchRemove(root, child); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if object, ok := value.(map[string]any); ok {
+			kind := derefPathValue(reflect.ValueOf(existing)).Kind()
+			if exists && (kind == reflect.Map || kind == reflect.Struct) {
+				if err := applyMergePatch(root, child, object); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if err := patchSet(root, child, withoutNulls(value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyMergePatch applies an RFC 7386 Merge Patch to target, a pointer or
+// a map: null deletes a member, objects are merged and any other value
+// replaces the member.
+func ApplyMergePatch(target any, patch map[string]any) error {
+	return patchTarget(target, func(root reflect.Value) error {
+		return applyMergePatch(root, []string{}, patch)
+	})
+}
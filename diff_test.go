@@ -0,0 +1,136 @@
+package utils_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	utils "github.com/jingyuexing/go-utils"
+)
+
+type diffAddress struct {
+	City string `json:"city"`
+	Zip  string `json:"zip"`
+}
+
+type diffUser struct {
+	Name      string `json:"name"`
+	Age       int
+	Tags      []string
+	Address   *diffAddress `json:"address"`
+	Meta      map[string]any
+	UpdatedAt int64 `diff:"-"`
+	Password  string
+}
+
+func TestDiff(t *testing.T) {
+	before := diffUser{
+		Name: "ann", Age: 30, Tags: []string{"a", "b", "c"},
+		Address:   &diffAddress{City: "Paris", Zip: "75001"},
+		Meta:      map[string]any{"x": 1, "old": true},
+		UpdatedAt: 1, Password: "p1",
+	}
+	after := diffUser{
+		Name: "ann", Age: 31, Tags: []string{"a", "z"},
+		Address:   &diffAddress{City: "Lyon", Zip: "75001"},
+		Meta:      map[string]any{"x": 1.0, "new/key": "v"},
+		UpdatedAt: 2, Password: "p2",
+	}
+	changes := utils.Diff(before, after, utils.WithDiffIgnore("Password"))
+	got := []string{}
+	for _, change := range changes {
+		got = append(got, change.String())
+	}
+	expected := []string{
+		"changed /address/city: Paris -> Lyon",
+		"changed /age: 30 -> 31",
+		"added /meta/new~1key: v",
+		"removed /meta/old: true",
+		"changed /tags/1: b -> z",
+		"removed /tags/2: c",
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s", expected, got))
+	}
+	if len(utils.Diff(before, before)) != 0 {
+		t.Error(fmt.Sprintf("Expect: no changes, but got %v", utils.Diff(before, before)))
+	}
+
+	patch, _ := json.Marshal(changes.JSONPatch())
+	expectedPatch := `[{"op":"replace","path":"/address/city","value":"Lyon"},{"op":"replace","path":"/age","value":31},` +
+		`{"op":"add","path":"/meta/new~1key","value":"v"},{"op":"remove","path":"/meta/old"},` +
+		`{"op":"replace","path":"/tags/1","value":"z"},{"op":"remove","path":"/tags/2"}]`
+	if string(patch) != expectedPatch {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s", expectedPatch, patch))
+	}
+	merge, _ := json.Marshal(changes.MergePatch())
+	expectedMerge := `{"address":{"city":"Lyon"},"age":31,"meta":{"new/key":"v","old":null},"tags":["a","z"]}`
+	if string(merge) != expectedMerge {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s", expectedMerge, merge))
+	}
+
+	target := before
+	target.Address = &diffAddress{City: "Paris", Zip: "75001"}
+	if err := utils.ApplyPatch(&target, changes.JSONPatch()); err != nil {
+		t.Fatal(err)
+	}
+	if remaining := utils.Diff(target, after, utils.WithDiffIgnore("Password")); len(remaining) != 0 {
+		t.Error(fmt.Sprintf("Expect: the patched value to equal after, but got %v", remaining))
+	}
+	if before.Address.City != "Paris" {
+		t.Error("Expect: the patch not to modify shared pointers")
+	}
+
+	var mergePatch map[string]any
+	json.Unmarshal(merge, &mergePatch)
+	target = before
+	if err := utils.ApplyMergePatch(&target, mergePatch); err != nil {
+		t.Fatal(err)
+	}
+	if remaining := utils.Diff(target, after, utils.WithDiffIgnore("Password")); len(remaining) != 0 {
+		t.Error(fmt.Sprintf("Expect: the merged value to equal after, but got %v", remaining))
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	document := map[string]any{"list": []any{1, 2}, "a": map[string]any{"b": "c"}}
+	var patch []utils.PatchOperation
+	json.Unmarshal([]byte(`[
+		{"op": "add", "path": "/list/1", "value": 9},
+		{"op": "add", "path": "/list/-", "value": 3},
+		{"op": "copy", "from": "/a", "path": "/copy"},
+		{"op": "move", "from": "/a/b", "path": "/moved"},
+		{"op": "test", "path": "/list/0", "value": 1},
+		{"op": "replace", "path": "/copy/b", "value": null}
+	]`), &patch)
+	if err := utils.ApplyPatch(document, patch); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]any{"list": []any{1, 9.0, 2, 3.0}, "a": map[string]any{}, "copy": map[string]any{"b": nil}, "moved": "c"}
+	if !reflect.DeepEqual(document, expected) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", expected, document))
+	}
+
+	failing := []utils.PatchOperation{{Op: "remove", Path: "/moved"}, {Op: "test", Path: "/list/0", Value: 2}}
+	if err := utils.ApplyPatch(document, failing); !errors.Is(err, utils.ErrPatchTest) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrPatchTest, err))
+	}
+	if document["moved"] != "c" {
+		t.Error("Expect: a failed patch to leave the document unchanged")
+	}
+	for _, operation := range []utils.PatchOperation{{Op: "replace", Path: "/missing", Value: 1}, {Op: "add", Path: "/list/9", Value: 1}, {Op: "jump", Path: ""}} {
+		if err := utils.ApplyPatch(document, []utils.PatchOperation{operation}); !errors.Is(err, utils.ErrInvalidPatch) {
+			t.Error(fmt.Sprintf("Expect: %v, but got %v (%v)", utils.ErrInvalidPatch, err, operation))
+		}
+	}
+
+	merged := map[string]any{"title": "Goodbye!", "author": map[string]any{"givenName": "John", "familyName": "Doe"}, "tags": []any{"example", "sample"}}
+	utils.ApplyMergePatch(merged, map[string]any{"title": "Hello!", "author": map[string]any{"familyName": nil}, "tags": []any{"example"}, "phone": "+01"})
+	expected = map[string]any{"title": "Hello!", "author": map[string]any{"givenName": "John"}, "tags": []any{"example"}, "phone": "+01"}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", expected, merged))
+	}
+}
@@ -0,0 +1,222 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	durationDay   time.Duration = 24 * time.Hour
+	durationWeek  time.Duration = 7 * durationDay
+	durationMonth time.Duration = 30 * durationDay
+	durationYear  time.Duration = 365 * durationDay
+)
+
+var humanDurationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond, "nsec": time.Nanosecond, "nanosecond": time.Nanosecond, "nanoseconds": time.Nanosecond,
+	"us": time.Microsecond, "µs": time.Microsecond, "μs": time.Microsecond, "usec": time.Microsecond,
+	"microsecond": time.Microsecond, "microseconds": time.Microsecond,
+	"ms": time.Millisecond, "msec": time.Millisecond, "millisecond": time.Millisecond, "milliseconds": time.Millisecond,
+	"s": time.Second, "sec": time.Second, "secs": time.Second, "second": time.Second, "seconds": time.Second,
+	"m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"h": time.Hour, "hr": time.Hour, "hrs": time.Hour, "hour": time.Hour, "hours": time.Hour,
+	"d": durationDay, "day": durationDay, "days": durationDay,
+	"w": durationWeek, "wk": durationWeek, "wks": durationWeek, "week": durationWeek, "weeks": durationWeek,
+	"mo": durationMonth, "mon": durationMonth, "month": durationMonth, "months": durationMonth,
+	"y": durationYear, "yr": durationYear, "yrs": durationYear, "year": durationYear, "years": durationYear,
+
+	"纳秒": time.Nanosecond,
+	"微秒": time.Microsecond,
+	"毫秒": time.Millisecond,
+	"秒":  time.Second, "秒钟": time.Second,
+	"分": time.Minute, "分钟": time.Minute,
+	"时": time.Hour, "小时": time.Hour, "个小时": time.Hour, "钟头": time.Hour, "个钟头": time.Hour,
+	"天": durationDay, "日": durationDay,
+	"周": durationWeek, "星期": durationWeek, "个星期": durationWeek, "礼拜": durationWeek, "个礼拜": durationWeek,
+	"月": durationMonth, "个月": durationMonth,
+	"年": durationYear,
+}
+
+func isDurationSeparator(char rune) bool {
+	return unicode.IsSpace(char) || char == ',' || char == '，' || char == '、'
+}
+
+func isDurationNumber(char rune) bool {
+	return IsNumberic(char) || char == '.'
+}
+
+// ParseHumanDuration parses a human readable duration such as "1h30m",
+// "2 hours 15 minutes", "1.5d" or "3天2小时". A leading "-" negates the
+// whole duration. Months count as 30 days and years as 365 days.
+func ParseHumanDuration(duration string) (time.Duration, error) {
+	source := []rune(strings.TrimSpace(duration))
+	if len(source) == 0 {
+		return 0, fmt.Errorf("invalid duration %q: empty string", duration)
+	}
+	sign := 1.0
+	idx := 0
+	if source[0] == '-' || source[0] == '+' {
+		if source[0] == '-' {
+			sign = -1
+		}
+		idx++
+	}
+
+	total := 0.0
+	components := 0
+	for idx < len(source) {
+		if isDurationSeparator(source[idx]) {
+			idx++
+			continue
+		}
+		start := idx
+		for idx < len(source) && isDurationNumber(source[idx]) {
+			idx++
+		}
+		number := string(source[start:idx])
+		if number == "" {
+			word := start
+			for idx < len(source) && !isDurationSeparator(source[idx]) && !isDurationNumber(source[idx]) {
+				idx++
+			}
+			if strings.EqualFold(string(source[word:idx]), "and") {
+				continue
+			}
+			return 0, fmt.Errorf("invalid duration %q: expected a number at %q", duration, string(source[word:idx]))
+		}
+		for idx < len(source) && unicode.IsSpace(source[idx]) {
+			idx++
+		}
+		unitStart := idx
+		for idx < len(source) && !isDurationSeparator(source[idx]) && !isDurationNumber(source[idx]) && source[idx] != '-' {
+			idx++
+		}
+		unitName := string(source[unitStart:idx])
+		value, err := strconv.ParseFloat(number, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q: bad number %q", duration, number)
+		}
+		if unitName == "" {
+			if value == 0 {
+				components++
+				continue
+			}
+			return 0, fmt.Errorf("invalid duration %q: missing unit after %q", duration, number)
+		}
+		unit, ok := humanDurationUnits[strings.ToLower(unitName)]
+		if !ok {
+			return 0, fmt.Errorf("invalid duration %q: unknown unit %q", duration, unitName)
+		}
+		total += value * float64(unit)
+		components++
+	}
+	if components == 0 {
+		return 0, fmt.Errorf("invalid duration %q: no value", duration)
+	}
+	total = math.Round(total * sign)
+	if total > math.MaxInt64 || total < math.MinInt64 {
+		return 0, fmt.Errorf("invalid duration %q: out of range", duration)
+	}
+	return time.Duration(total), nil
+}
+
+// DurationLocale holds the unit names used by FormatHumanDuration.
+type DurationLocale struct {
+	Singular  []string
+	Plural    []string
+	Separator string
+	// Spaced puts a space between the number and the unit name.
+	Spaced bool
+}
+
+var durationFormatUnits = []time.Duration{
+	durationDay, time.Hour, time.Minute, time.Second, time.Millisecond, time.Microsecond, time.Nanosecond,
+}
+
+var durationCompactNames = []string{"d", "h", "m", "s", "ms", "µs", "ns"}
+
+var durationLocales = map[string]DurationLocale{
+	"": {
+		Singular: durationCompactNames,
+	},
+	"en": {
+		Singular:  []string{"day", "hour", "minute", "second", "millisecond", "microsecond", "nanosecond"},
+		Plural:    []string{"days", "hours", "minutes", "seconds", "milliseconds", "microseconds", "nanoseconds"},
+		Separator: " ",
+		Spaced:    true,
+	},
+	"zh": {
+		Singular: []string{"天", "小时", "分钟", "秒", "毫秒", "微秒", "纳秒"},
+	},
+}
+
+// RegisterDurationLocale adds or replaces the unit names used by FormatHumanDuration
+// for the given locale. Names are ordered from days down to nanoseconds, units
+// missing at the end use the compact names.
+func RegisterDurationLocale(locale string, names DurationLocale) {
+	durationLocales[locale] = names
+}
+
+// FormatHumanDuration renders d using at most `precision` units, starting at
+// the largest non-zero unit; the last unit is rounded. A precision of 0 or
+// less keeps every unit. Supported locales are "" (compact, e.g. "1h30m"),
+// "en" ("1 hour 30 minutes") and "zh" ("1小时30分钟"); unknown locales fall
+// back to the compact form.
+func FormatHumanDuration(d time.Duration, precision int, locale string) string {
+	names, ok := durationLocales[locale]
+	if !ok {
+		names = durationLocales[""]
+	}
+	sign := ""
+	if d < 0 {
+		sign = "-"
+		if d == math.MinInt64 {
+			d++
+		}
+		d = -d
+	}
+
+	if precision > 0 {
+		first := len(durationFormatUnits) - 1
+		for i, unit := range durationFormatUnits {
+			if d >= unit {
+				first = i
+				break
+			}
+		}
+		last := min(first+precision-1, len(durationFormatUnits)-1)
+		d = d.Round(durationFormatUnits[last])
+	}
+
+	parts := []string{}
+	for i, unit := range durationFormatUnits {
+		count := d / unit
+		if count == 0 || (precision > 0 && len(parts) >= precision) {
+			continue
+		}
+		d -= count * unit
+		parts = append(parts, formatDurationPart(int64(count), i, names))
+	}
+	if len(parts) == 0 {
+		return formatDurationPart(0, 3, names)
+	}
+	return sign + strings.Join(parts, names.Separator)
+}
+
+func formatDurationPart(count int64, unitIndex int, names DurationLocale) string {
+	name := durationCompactNames[unitIndex]
+	if len(names.Singular) > unitIndex {
+		name = names.Singular[unitIndex]
+	}
+	if count != 1 && len(names.Plural) > unitIndex {
+		name = names.Plural[unitIndex]
+	}
+	if names.Spaced {
+		return strconv.FormatInt(count, 10) + " " + name
+	}
+	return strconv.FormatInt(count, 10) + name
+}
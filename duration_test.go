@@ -0,0 +1,83 @@
+package utils_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	utils "github.com/jingyuexing/go-utils"
+)
+
+func TestParseHumanDuration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"1h30m":                 90 * time.Minute,
+		"1.5h":                  90 * time.Minute,
+		"2 hours 15 minutes":    2*time.Hour + 15*time.Minute,
+		"1 hour and 30 minutes": 90 * time.Minute,
+		"3 days":                72 * time.Hour,
+		"1w 2d":                 9 * 24 * time.Hour,
+		"3天2小时":                 74 * time.Hour,
+		"1小时30分钟":               90 * time.Minute,
+		"-1h30m":                -90 * time.Minute,
+		"250ms":                 250 * time.Millisecond,
+		"0":                     0,
+		"1d, 2h":                26 * time.Hour,
+	}
+	for source, expect := range cases {
+		result, err := utils.ParseHumanDuration(source)
+		if err != nil {
+			t.Error(fmt.Sprintf("ParseHumanDuration(%q) unexpected error %s", source, err))
+			continue
+		}
+		if result != expect {
+			t.Error(fmt.Sprintf("ParseHumanDuration(%q) Expect: %s, but got %s", source, expect, result))
+		}
+	}
+
+	for _, source := range []string{"", "h", "12", "3 fortnights", "1h 2"} {
+		if _, err := utils.ParseHumanDuration(source); err == nil {
+			t.Error(fmt.Sprintf("ParseHumanDuration(%q) should return an error", source))
+		}
+	}
+}
+
+func TestFormatHumanDuration(t *testing.T) {
+	d := 26*time.Hour + 29*time.Minute + 59*time.Second
+
+	cases := []struct {
+		precision int
+		locale    string
+		expect    string
+	}{
+		{0, "", "1d2h29m59s"},
+		{2, "", "1d2h"},
+		{3, "", "1d2h30m"},
+		{2, "en", "1 day 2 hours"},
+		{0, "zh", "1天2小时29分钟59秒"},
+	}
+	for _, c := range cases {
+		result := utils.FormatHumanDuration(d, c.precision, c.locale)
+		if result != c.expect {
+			t.Error(fmt.Sprintf("Expect: %s, but got %s", c.expect, result))
+		}
+	}
+	if utils.FormatHumanDuration(-90*time.Minute, 0, "") != "-1h30m" {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s", "-1h30m", utils.FormatHumanDuration(-90*time.Minute, 0, "")))
+	}
+	if utils.FormatHumanDuration(0, 0, "en") != "0 seconds" {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s", "0 seconds", utils.FormatHumanDuration(0, 0, "en")))
+	}
+
+	utils.RegisterDurationLocale("short", utils.DurationLocale{Singular: []string{"day", "hour"}, Separator: " "})
+	if utils.FormatHumanDuration(d, 0, "short") != "1day 2hour 29m 59s" {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s", "1day 2hour 29m 59s", utils.FormatHumanDuration(d, 0, "short")))
+	}
+
+	for _, locale := range []string{"", "en", "zh"} {
+		text := utils.FormatHumanDuration(d+1500*time.Microsecond, 0, locale)
+		back, err := utils.ParseHumanDuration(text)
+		if err != nil || back != d+1500*time.Microsecond {
+			t.Error(fmt.Sprintf("round trip of %q failed, got %s %v", text, back, err))
+		}
+	}
+}
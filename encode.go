@@ -0,0 +1,219 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var ErrEncode = errors.New("encode failed")
+
+type EncodeOptions struct {
+	// TagName is the struct tag holding field names and options, "json" by
+	// default.
+	TagName string
+	// KeyFunc names the fields without a tag name, SnakeCase of the field
+	// name by default. Fields named "" are skipped.
+	KeyFunc KeyFunc
+}
+
+func WithEncodeTag(tag string) DataOption[EncodeOptions] {
+	return func(o *EncodeOptions) {
+		o.TagName = tag
+	}
+}
+
+func WithEncodeKeyFunc(key KeyFunc) DataOption[EncodeOptions] {
+	return func(o *EncodeOptions) {
+		o.KeyFunc = key
+	}
+}
+
+type encoder struct {
+	options EncodeOptions
+	// visiting holds the pointers on the current path to detect cycles.
+	visiting map[pointerVisit]bool
+}
+
+// isEncodeLeaf reports whether value is kept as is, like dates and numbers.
+func isEncodeLeaf(typ reflect.Type) bool {
+	switch typ {
+	case timeType, dateTimeType, bigNumberType:
+		return true
+	}
+	return typ.Implements(textMarshalerType)
+}
+
+// isEmptyValue follows the omitempty rules of encoding/json.
+func isEmptyValue(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return value.Len() == 0
+	case reflect.Pointer, reflect.Interface:
+		return value.IsNil()
+	case reflect.Struct:
+		return false
+	}
+	return value.IsZero()
+}
+
+func (e *encoder) encode(path string, value reflect.Value) (any, error) {
+	for value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil, nil
+		}
+		value = value.Elem()
+	}
+	if !value.IsValid() {
+		return nil, nil
+	}
+	if isEncodeLeaf(value.Type()) {
+		return value.Interface(), nil
+	}
+	switch value.Kind() {
+	case reflect.Slice:
+		if value.IsNil() {
+			return nil, nil
+		}
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			return value.Interface(), nil
+		}
+	case reflect.Pointer, reflect.Map:
+		if value.IsNil() {
+			return nil, nil
+		}
+		visit := pointerVisit{value.Pointer(), value.Type()}
+		if e.visiting[visit] {
+			return nil, fmt.Errorf("%w: cycle at %q", ErrEncode, path)
+		}
+		e.visiting[visit] = true
+		defer delete(e.visiting, visit)
+	}
+	switch value.Kind() {
+	case reflect.Pointer:
+		return e.encode(path, value.Elem())
+	case reflect.Struct:
+		result := map[string]any{}
+		if err := e.encodeStruct(path, value, result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	case reflect.Map:
+		result := make(map[string]any, value.Len())
+		iter := value.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key().Interface())
+			item, err := e.encode(joinDecodePath(path, key), iter.Value())
+			if err != nil {
+				return nil, err
+			}
+			result[key] = item
+		}
+		return result, nil
+	case reflect.Slice, reflect.Array:
+		result := make([]any, value.Len())
+		for i := range result {
+			item, err := e.encode(joinDecodePath(path, strconv.Itoa(i)), value.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			result[i] = item
+		}
+		return result, nil
+	}
+	return value.Interface(), nil
+}
+
+// encodeStruct writes the fields of value into result. Fields of embedded
+// structs without a tag name are flattened, the outer fields win.
+func (e *encoder) encodeStruct(path string, value reflect.Value, result map[string]any) error {
+	embedded := map[string]any{}
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		tag := field.Tag.Get(e.options.TagName)
+		if tag == "-" {
+			continue
+		}
+		name, flags, _ := strings.Cut(tag, ",")
+		flags = "," + flags + ","
+		fieldValue := value.Field(i)
+		if field.Anonymous && name == "" {
+			inner := fieldValue
+			if inner.Kind() == reflect.Pointer && inner.Type().Elem().Kind() == reflect.Struct {
+				if inner.IsNil() {
+					continue
+				}
+				inner = inner.Elem()
+			}
+			if inner.Kind() == reflect.Struct && !isEncodeLeaf(inner.Type()) {
+				if err := e.encodeStruct(path, inner, embedded); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = e.options.KeyFunc(field)
+		}
+		if name == "" || (strings.Contains(flags, ",omitempty,") && isEmptyValue(fieldValue)) {
+			continue
+		}
+		fieldPath := joinDecodePath(path, name)
+		if strings.Contains(flags, ",string,") {
+			switch fieldValue.Kind() {
+			case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+				reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+				reflect.Float32, reflect.Float64, reflect.String:
+				result[name] = fmt.Sprint(fieldValue.Interface())
+				continue
+			}
+		}
+		item, err := e.encode(fieldPath, fieldValue)
+		if err != nil {
+			return err
+		}
+		result[name] = item
+	}
+	for key, item := range embedded {
+		if _, ok := result[key]; !ok {
+			result[key] = item
+		}
+	}
+	return nil
+}
+
+// Struct2Map converts a struct, or a pointer to one, into a map following
+// the json tag rules: fields are named by their tag or SnakeCase of their
+// name, "-" skips a field, omitempty skips empty values and string
+// formats numbers and bools as text. Nested structs, slices and maps are
+// converted too, dates and big numbers are kept as values. The result can
+// be decoded back with Map2Struct.
+func Struct2Map(v any, opts ...DataOption[EncodeOptions]) (map[string]any, error) {
+	options := EncodeOptions{TagName: "json", KeyFunc: func(field reflect.StructField) string {
+		return SnakeCase(field.Name)
+	}}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: expected a struct, got %T", ErrEncode, v)
+	}
+	e := &encoder{options: options, visiting: map[pointerVisit]bool{}}
+	if root := reflect.ValueOf(v); root.Kind() == reflect.Pointer {
+		e.visiting[pointerVisit{root.Pointer(), root.Type()}] = true
+	}
+	result := map[string]any{}
+	if err := e.encodeStruct("", value, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,100 @@
+package utils_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	utils "github.com/jingyuexing/go-utils"
+)
+
+type EncodeMeta struct {
+	Version int    `json:"version"`
+	Owner   string `json:"owner,omitempty"`
+}
+
+type encodeNode struct {
+	*EncodeMeta
+	Name     string        `json:"name"`
+	Count    int           `json:"count,string"`
+	Hidden   string        `json:"-"`
+	Dash     string        `json:"-,"`
+	Note     string        `json:"note,omitempty"`
+	Children []*encodeNode `json:"children,omitempty"`
+	Labels   map[string]int
+	Timeout  time.Duration
+	Parent   *encodeNode `json:"parent,omitempty"`
+	private  int
+}
+
+func TestStruct2Map(t *testing.T) {
+	node := &encodeNode{
+		EncodeMeta: &EncodeMeta{Version: 2},
+		Name:       "root",
+		Count:      3,
+		Hidden:     "x",
+		Dash:       "d",
+		Children:   []*encodeNode{{Name: "leaf", Labels: map[string]int{"a": 1}}},
+		Timeout:    time.Second,
+		private:    1,
+	}
+	result, err := utils.Struct2Map(node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]any{
+		"version": 2,
+		"name":    "root",
+		"count":   "3",
+		"-":       "d",
+		"children": []any{map[string]any{
+			"name": "leaf", "count": "0", "-": "", "labels": map[string]any{"a": 1}, "timeout": time.Duration(0),
+		}},
+		"labels":  nil,
+		"timeout": time.Second,
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", expected, result))
+	}
+
+	decoded := encodeNode{}
+	if err := utils.Map2Struct(result, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Version != 2 || decoded.Count != 3 || decoded.Dash != "d" || decoded.Children[0].Labels["a"] != 1 || decoded.Timeout != time.Second {
+		t.Error(fmt.Sprintf("Expect: the map to decode back, but got %+v", decoded))
+	}
+
+	node.Children[0].Parent = node
+	if _, err := utils.Struct2Map(node); !errors.Is(err, utils.ErrEncode) || !strings.Contains(err.Error(), "children.0.parent") {
+		t.Error(fmt.Sprintf("Expect: %v at children.0.parent, but got %v", utils.ErrEncode, err))
+	}
+	type inner struct{ X int }
+	type outer struct {
+		In inner
+		P  *inner
+	}
+	alias := outer{In: inner{X: 1}}
+	alias.P = &alias.In
+	if result, err := utils.Struct2Map(&alias); err != nil || !reflect.DeepEqual(result["p"], map[string]any{"x": 1}) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v %v", map[string]any{"x": 1}, result, err))
+	}
+	if _, err := utils.Struct2Map([]int{1}); !errors.Is(err, utils.ErrEncode) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrEncode, err))
+	}
+
+	type form struct {
+		UserName string `form:"user"`
+		Age      int
+		Created  utils.DateTime
+	}
+	created := utils.From(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC))
+	upper, _ := utils.Struct2Map(form{UserName: "ann", Age: 3, Created: created}, utils.WithEncodeTag("form"),
+		utils.WithEncodeKeyFunc(func(field reflect.StructField) string { return strings.ToUpper(field.Name) }))
+	if !reflect.DeepEqual(upper, map[string]any{"user": "ann", "AGE": 3, "CREATED": created}) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", map[string]any{"user": "ann", "AGE": 3, "CREATED": created}, upper))
+	}
+}
@@ -0,0 +1,204 @@
+package utils
+
+import "time"
+
+// WeekSystem selects the rule used to number the weeks of a year.
+type WeekSystem int
+
+const (
+	// WeekISO numbers weeks by ISO 8601: weeks start on Monday and week 1
+	// is the week containing the first Thursday of the year.
+	WeekISO WeekSystem = iota
+	// WeekUS starts weeks on Sunday, week 1 is the week containing January 1.
+	WeekUS
+	// WeekMiddleEast starts weeks on Saturday, week 1 is the week containing January 1.
+	WeekMiddleEast
+)
+
+// FiscalPattern describes how a fiscal quarter is split into periods.
+type FiscalPattern int
+
+const (
+	// FiscalMonthly uses calendar months as fiscal periods.
+	FiscalMonthly FiscalPattern = iota
+	// Fiscal445 splits every 13 week quarter into 4, 4 and 5 week periods.
+	Fiscal445
+	// Fiscal454 splits every 13 week quarter into 4, 5 and 4 week periods.
+	Fiscal454
+	// Fiscal544 splits every 13 week quarter into 5, 4 and 4 week periods.
+	Fiscal544
+)
+
+// FiscalCalendar describes a fiscal year.
+//
+// With FiscalMonthly the fiscal year begins on the first day of StartMonth.
+// The retail patterns (4-4-5, 4-5-4, 5-4-4) begin the year on the WeekStart
+// day nearest to the first day of StartMonth, so every year has 52 or 53
+// weeks and the extra week is added to the last period of the fourth quarter.
+//
+// A fiscal year is named after the calendar year it ends in, unless
+// LabelByStartYear is set or the year starts in January.
+type FiscalCalendar struct {
+	StartMonth       time.Month
+	Pattern          FiscalPattern
+	WeekStart        time.Weekday
+	LabelByStartYear bool
+}
+
+func (cal FiscalCalendar) startMonth() time.Month {
+	if cal.StartMonth < time.January || cal.StartMonth > time.December {
+		return time.January
+	}
+	return cal.StartMonth
+}
+
+// dateOf drops the clock and location so that day arithmetic is not
+// affected by daylight saving changes.
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func daysBetween(from, to time.Time) int {
+	return int(to.Sub(from).Hours() / 24)
+}
+
+// YearStart returns the first day of the fiscal year that starts in the calendar year `year`.
+func (cal FiscalCalendar) YearStart(year int) time.Time {
+	anchor := time.Date(year, cal.startMonth(), 1, 0, 0, 0, 0, time.UTC)
+	if cal.Pattern == FiscalMonthly {
+		return anchor
+	}
+	diff := (int(cal.WeekStart) - int(anchor.Weekday()) + 7) % 7
+	if diff > 3 {
+		diff -= 7
+	}
+	return anchor.AddDate(0, 0, diff)
+}
+
+// locate returns the calendar year the fiscal year containing t starts in,
+// together with the first day of that fiscal year and of the next one.
+func (cal FiscalCalendar) locate(t time.Time) (int, time.Time, time.Time) {
+	date := dateOf(t)
+	startYear := date.Year()
+	if date.Before(cal.YearStart(startYear)) {
+		startYear--
+	} else if !date.Before(cal.YearStart(startYear + 1)) {
+		startYear++
+	}
+	return startYear, cal.YearStart(startYear), cal.YearStart(startYear + 1)
+}
+
+// Year returns the fiscal year containing t.
+func (cal FiscalCalendar) Year(t time.Time) int {
+	startYear, _, _ := cal.locate(t)
+	if cal.LabelByStartYear || cal.startMonth() == time.January {
+		return startYear
+	}
+	return startYear + 1
+}
+
+// Quarter returns the fiscal quarter (1-4) containing t.
+func (cal FiscalCalendar) Quarter(t time.Time) int {
+	return (cal.Period(t)-1)/3 + 1
+}
+
+// Period returns the fiscal period (1-12) containing t.
+func (cal FiscalCalendar) Period(t time.Time) int {
+	if cal.Pattern == FiscalMonthly {
+		return (int(t.Month())-int(cal.startMonth())+12)%12 + 1
+	}
+	week := cal.Week(t) - 1
+	quarter := min(week/13, 3)
+	weekOfQuarter := week - quarter*13
+	period := 0
+	for _, length := range cal.periodWeeks() {
+		if weekOfQuarter < length {
+			break
+		}
+		weekOfQuarter -= length
+		period++
+	}
+	return quarter*3 + min(period, 2) + 1
+}
+
+// Week returns the week of the fiscal year (1-53) containing t.
+func (cal FiscalCalendar) Week(t time.Time) int {
+	_, start, _ := cal.locate(t)
+	return daysBetween(start, dateOf(t))/7 + 1
+}
+
+// WeeksInYear returns the number of weeks in the fiscal year containing t.
+func (cal FiscalCalendar) WeeksInYear(t time.Time) int {
+	_, start, next := cal.locate(t)
+	days := daysBetween(start, next)
+	return (days + 6) / 7
+}
+
+func (cal FiscalCalendar) periodWeeks() []int {
+	switch cal.Pattern {
+	case Fiscal454:
+		return []int{4, 5, 4}
+	case Fiscal544:
+		return []int{5, 4, 4}
+	default:
+		return []int{4, 4, 5}
+	}
+}
+
+// WeekNumber returns the week of the year of t under the given week system.
+func WeekNumber(t time.Time, system WeekSystem) int {
+	switch system {
+	case WeekUS, WeekMiddleEast:
+		firstDay := time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		offset := int(firstDay.Weekday())
+		if system == WeekMiddleEast {
+			offset = (offset - int(time.Saturday) + 7) % 7
+		}
+		return (t.YearDay()-1+offset)/7 + 1
+	default:
+		_, week := t.ISOWeek()
+		return week
+	}
+}
+
+// Quarter returns the calendar quarter (1-4) of t.
+func Quarter(t time.Time) int {
+	return (int(t.Month())-1)/3 + 1
+}
+
+func WithFiscalCalendar(cal FiscalCalendar) DataOption[DateTime] {
+	return func(dt *DateTime) {
+		dt.fiscal = cal
+	}
+}
+
+func WithWeekSystem(system WeekSystem) DataOption[DateTime] {
+	return func(dt *DateTime) {
+		dt.weekSystem = system
+	}
+}
+
+func (dt DateTime) Quarter() int {
+	return Quarter(dt.time)
+}
+
+func (dt DateTime) FiscalYear() int {
+	return dt.fiscal.Year(dt.time)
+}
+
+func (dt DateTime) FiscalQuarter() int {
+	return dt.fiscal.Quarter(dt.time)
+}
+
+func (dt DateTime) FiscalPeriod() int {
+	return dt.fiscal.Period(dt.time)
+}
+
+func (dt DateTime) FiscalWeek() int {
+	return dt.fiscal.Week(dt.time)
+}
+
+// WeekNumber returns the week of the year under the given week system.
+func (dt DateTime) WeekNumber(system WeekSystem) int {
+	return WeekNumber(dt.time, system)
+}
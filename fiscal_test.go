@@ -0,0 +1,107 @@
+package utils_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	utils "github.com/jingyuexing/go-utils"
+)
+
+func TestQuarter(t *testing.T) {
+	dt := utils.From(time.Date(2024, time.August, 15, 0, 0, 0, 0, time.UTC))
+	if dt.Quarter() != 3 {
+		t.Error(fmt.Sprintf("Expect: %d, but got %d", 3, dt.Quarter()))
+	}
+	if dt.FiscalYear() != 2024 || dt.FiscalQuarter() != 3 {
+		t.Error(fmt.Sprintf("Expect: %s, but got %d Q%d", "2024 Q3", dt.FiscalYear(), dt.FiscalQuarter()))
+	}
+}
+
+func TestFiscalMonthly(t *testing.T) {
+	dt := utils.From(time.Date(2023, time.November, 15, 0, 0, 0, 0, time.UTC))
+	dt = dt.Set(utils.WithFiscalCalendar(utils.FiscalCalendar{StartMonth: time.October}))
+
+	if dt.FiscalYear() != 2024 {
+		t.Error(fmt.Sprintf("Expect: %d, but got %d", 2024, dt.FiscalYear()))
+	}
+	if dt.FiscalQuarter() != 1 {
+		t.Error(fmt.Sprintf("Expect: %d, but got %d", 1, dt.FiscalQuarter()))
+	}
+	if dt.FiscalPeriod() != 2 {
+		t.Error(fmt.Sprintf("Expect: %d, but got %d", 2, dt.FiscalPeriod()))
+	}
+	result := dt.Format("FYYYY/FQ/FP/Q")
+	if result != "2024/1/02/4" {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s", "2024/1/02/4", result))
+	}
+	if dt.AddMonth(2).FiscalQuarter() != 2 {
+		t.Error("the fiscal calendar should be kept after Add")
+	}
+}
+
+func TestFiscalRetail(t *testing.T) {
+	cal := utils.FiscalCalendar{
+		StartMonth:       time.February,
+		Pattern:          utils.Fiscal454,
+		WeekStart:        time.Sunday,
+		LabelByStartYear: true,
+	}
+	start := cal.YearStart(2023)
+	if !start.Equal(time.Date(2023, time.January, 29, 0, 0, 0, 0, time.UTC)) {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s", "2023-01-29", start))
+	}
+
+	cases := []struct {
+		date    time.Time
+		year    int
+		quarter int
+		period  int
+		week    int
+	}{
+		{time.Date(2023, time.January, 29, 0, 0, 0, 0, time.UTC), 2023, 1, 1, 1},
+		{time.Date(2023, time.March, 1, 0, 0, 0, 0, time.UTC), 2023, 1, 2, 5},
+		{time.Date(2023, time.April, 30, 0, 0, 0, 0, time.UTC), 2023, 2, 4, 14},
+		{time.Date(2024, time.February, 3, 0, 0, 0, 0, time.UTC), 2023, 4, 12, 53},
+		{time.Date(2024, time.February, 4, 0, 0, 0, 0, time.UTC), 2024, 1, 1, 1},
+	}
+	for _, c := range cases {
+		if cal.Year(c.date) != c.year || cal.Quarter(c.date) != c.quarter ||
+			cal.Period(c.date) != c.period || cal.Week(c.date) != c.week {
+			t.Error(fmt.Sprintf("%s Expect: %d Q%d P%d W%d, but got %d Q%d P%d W%d",
+				c.date.Format("2006-01-02"), c.year, c.quarter, c.period, c.week,
+				cal.Year(c.date), cal.Quarter(c.date), cal.Period(c.date), cal.Week(c.date)))
+		}
+	}
+	if cal.WeeksInYear(time.Date(2023, time.June, 1, 0, 0, 0, 0, time.UTC)) != 53 {
+		t.Error("fiscal 2023 should have 53 weeks")
+	}
+}
+
+func TestWeekSystem(t *testing.T) {
+	sunday := time.Date(2024, time.January, 7, 0, 0, 0, 0, time.UTC)
+	if utils.WeekNumber(sunday, utils.WeekISO) != 1 {
+		t.Error(fmt.Sprintf("Expect: %d, but got %d", 1, utils.WeekNumber(sunday, utils.WeekISO)))
+	}
+	if utils.WeekNumber(sunday, utils.WeekUS) != 2 {
+		t.Error(fmt.Sprintf("Expect: %d, but got %d", 2, utils.WeekNumber(sunday, utils.WeekUS)))
+	}
+	saturday := time.Date(2024, time.January, 6, 0, 0, 0, 0, time.UTC)
+	if utils.WeekNumber(saturday, utils.WeekMiddleEast) != 2 {
+		t.Error(fmt.Sprintf("Expect: %d, but got %d", 2, utils.WeekNumber(saturday, utils.WeekMiddleEast)))
+	}
+
+	dt := utils.From(sunday).Set(utils.WithWeekSystem(utils.WeekUS))
+	if dt.WeekOfYear() != 2 {
+		t.Error(fmt.Sprintf("Expect: %d, but got %d", 2, dt.WeekOfYear()))
+	}
+	if dt.Format("YYYY-ww") != "2024-02" {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s", "2024-02", dt.Format("YYYY-ww")))
+	}
+	if utils.DateTimeFormat(sunday, "YYYY-ww") != "2024-01" {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s", "2024-01", utils.DateTimeFormat(sunday, "YYYY-ww")))
+	}
+	if utils.DateTimeFormat(sunday, "YYYY Q now") != "2024 Q now" {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s", "2024 Q now", utils.DateTimeFormat(sunday, "YYYY Q now")))
+	}
+}
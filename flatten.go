@@ -0,0 +1,160 @@
+package utils
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type FlattenOptions struct {
+	// MaxDepth stops flattening below this many keys, 0 means no limit.
+	MaxDepth int
+	// KeyCase converts each key, such as strings.ToUpper for environment
+	// variables.
+	KeyCase func(string) string
+}
+
+func WithFlattenDepth(depth int) DataOption[FlattenOptions] {
+	return func(o *FlattenOptions) {
+		o.MaxDepth = depth
+	}
+}
+
+func WithFlattenCase(keyCase func(string) string) DataOption[FlattenOptions] {
+	return func(o *FlattenOptions) {
+		o.KeyCase = keyCase
+	}
+}
+
+func newFlattenOptions(opts []DataOption[FlattenOptions]) FlattenOptions {
+	options := FlattenOptions{KeyCase: func(key string) string { return key }}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+func flattenValue(value reflect.Value, prefix string, depth int, delimiter string, options FlattenOptions, result map[string]any) {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			result[prefix] = nil
+			return
+		}
+		value = value.Elem()
+	}
+	join := func(key string) string {
+		key = options.KeyCase(key)
+		if prefix == "" {
+			return key
+		}
+		return prefix + delimiter + key
+	}
+	// dates and other text values are leaves
+	leaf := !value.IsValid() || value.Type().Implements(textMarshalerType) ||
+		(options.MaxDepth > 0 && depth >= options.MaxDepth)
+	if !leaf {
+		switch value.Kind() {
+		case reflect.Map:
+			if value.Len() == 0 {
+				break
+			}
+			iter := value.MapRange()
+			for iter.Next() {
+				flattenValue(iter.Value(), join(fmt.Sprint(iter.Key().Interface())), depth+1, delimiter, options, result)
+			}
+			return
+		case reflect.Slice, reflect.Array:
+			if value.Len() == 0 {
+				break
+			}
+			for i := 0; i < value.Len(); i++ {
+				flattenValue(value.Index(i), join(strconv.Itoa(i)), depth+1, delimiter, options, result)
+			}
+			return
+		case reflect.Struct:
+			for _, field := range reflect.VisibleFields(value.Type()) {
+				if !field.IsExported() || field.Anonymous || field.Tag.Get("json") == "-" {
+					continue
+				}
+				fieldValue, err := value.FieldByIndexErr(field.Index)
+				if err != nil {
+					continue
+				}
+				flattenValue(fieldValue, join(GetFieldName(field)), depth+1, delimiter, options, result)
+			}
+			return
+		}
+	}
+	if value.IsValid() && value.CanInterface() {
+		result[prefix] = value.Interface()
+	}
+}
+
+// Flatten turns nested maps, structs, slices and pointers into one map
+// keyed by paths, such as {"server.ports.0": 80}. Struct fields are named
+// by GetFieldName, empty maps and slices are kept as values.
+func Flatten(data any, delimiter string, opts ...DataOption[FlattenOptions]) map[string]any {
+	if delimiter == "" {
+		delimiter = "."
+	}
+	result := map[string]any{}
+	flattenValue(reflect.ValueOf(data), "", 0, delimiter, newFlattenOptions(opts), result)
+	if value, ok := result[""]; ok && len(result) == 1 && value == nil {
+		delete(result, "")
+	}
+	return result
+}
+
+// indexedToSlice turns maps keyed by 0..n-1 back into slices.
+func indexedToSlice(value any) any {
+	object, ok := value.(map[string]any)
+	if !ok || len(object) == 0 {
+		return value
+	}
+	for key, item := range object {
+		object[key] = indexedToSlice(item)
+	}
+	list := make([]any, len(object))
+	for key, item := range object {
+		index, err := strconv.Atoi(key)
+		if err != nil || index < 0 || index >= len(list) || strconv.Itoa(index) != key {
+			return object
+		}
+		list[index] = item
+	}
+	return list
+}
+
+// Unflatten rebuilds the nested maps of Flatten, keys made of indexes
+// become slices again.
+func Unflatten(flat map[string]any, delimiter string, opts ...DataOption[FlattenOptions]) (map[string]any, error) {
+	if delimiter == "" {
+		delimiter = "."
+	}
+	options := newFlattenOptions(opts)
+	keys := make([]string, 0, len(flat))
+	for key := range flat {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	result := map[string]any{}
+	root := reflect.ValueOf(result)
+	for _, key := range keys {
+		segments := strings.Split(key, delimiter)
+		for i, segment := range segments {
+			segments[i] = options.KeyCase(segment)
+		}
+		if _, err := setNestedValue(root, root.Type(), segments, reflect.ValueOf(flat[key])); err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	for key, item := range result {
+		result[key] = indexedToSlice(item)
+	}
+	return result, nil
+}
@@ -0,0 +1,80 @@
+package utils_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	utils "github.com/jingyuexing/go-utils"
+)
+
+func TestFlatten(t *testing.T) {
+	type Server struct {
+		Host      string `json:"host"`
+		Ports     []int
+		MaxConns  int
+		StartedAt time.Time
+		Secret    string `json:"-"`
+	}
+	type Config struct {
+		Name   string `json:"name"`
+		Server *Server
+		Labels map[string]string
+		Empty  []string
+		Extra  any
+	}
+	started := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	config := Config{
+		Name:   "app",
+		Server: &Server{Host: "localhost", Ports: []int{80, 443}, MaxConns: 10, StartedAt: started, Secret: "x"},
+		Labels: map[string]string{"env": "dev"},
+		Empty:  []string{},
+	}
+	expected := map[string]any{
+		"name":              "app",
+		"server.host":       "localhost",
+		"server.ports.0":    80,
+		"server.ports.1":    443,
+		"server.max_conns":  10,
+		"server.started_at": started,
+		"labels.env":        "dev",
+		"empty":             []string{},
+		"extra":             nil,
+	}
+	flat := utils.Flatten(&config, ".")
+	if !reflect.DeepEqual(flat, expected) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", expected, flat))
+	}
+
+	nested, err := utils.Unflatten(flat, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for path, value := range flat {
+		if got := utils.AccessNested(nested, path, "."); !reflect.DeepEqual(got, value) {
+			t.Error(fmt.Sprintf("Expect: %v at %s, but got %v", value, path, got))
+		}
+	}
+	if ports, ok := utils.AccessNested(nested, "server.ports", ".").([]any); !ok || len(ports) != 2 {
+		t.Error(fmt.Sprintf("Expect: ports to be a slice, but got %v", utils.AccessNested(nested, "server.ports", ".")))
+	}
+
+	env := utils.Flatten(config, "_", utils.WithFlattenCase(strings.ToUpper), utils.WithFlattenDepth(2))
+	if env["SERVER_HOST"] != "localhost" || !reflect.DeepEqual(env["SERVER_PORTS"], []int{80, 443}) {
+		t.Error(fmt.Sprintf("Expect: upper case keys with ports kept at depth 2, but got %v", env))
+	}
+	back, _ := utils.Unflatten(map[string]any{"SERVER_HOST": "h"}, "_", utils.WithFlattenCase(strings.ToLower))
+	if !reflect.DeepEqual(back, map[string]any{"server": map[string]any{"host": "h"}}) {
+		t.Error(fmt.Sprintf("Expect: lower case nested keys, but got %v", back))
+	}
+	created := utils.CreateNestedObject("a.b", 1, ".")
+	if !reflect.DeepEqual(utils.Flatten(created, "."), map[string]any{"a.b": 1}) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", map[string]any{"a.b": 1}, utils.Flatten(created, ".")))
+	}
+	if _, err := utils.Unflatten(map[string]any{"a": 1, "a.b": 2}, "."); !errors.Is(err, utils.ErrNestedPath) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrNestedPath, err))
+	}
+}
@@ -0,0 +1,799 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidJSONPath = errors.New("invalid path expression")
+
+// PathMatch is a value found by a JSONPath with its concrete path, such as
+// $.items[0].name.
+type PathMatch struct {
+	Path  string
+	Value any
+}
+
+type pathSelectorKind int
+
+const (
+	pathName pathSelectorKind = iota
+	pathWildcard
+	pathIndex
+	pathSlice
+	pathFilter
+)
+
+type pathSelector struct {
+	kind   pathSelectorKind
+	name   string
+	index  int
+	slice  [3]*int
+	filter pathFilterExpr
+}
+
+type pathStep struct {
+	recursive bool
+	selectors []pathSelector
+}
+
+// JSONPath is a compiled path expression. It accepts dotted paths as
+// AccessNested does (items.0.name) and the JSONPath forms $.items[-1],
+// items.*.id, $..id, $.items[0,2], $.items[1:3] and filters such as
+// $.items[?(@.age > 18 && @.name =~ '^a')]. Struct fields are matched by
+// their json tag, then by their name.
+type JSONPath struct {
+	expression string
+	steps      []pathStep
+}
+
+// CompileJSONPath parses a path expression.
+func CompileJSONPath(expression string) (*JSONPath, error) {
+	parser := &pathParser{source: expression}
+	steps, err := parser.parsePath()
+	if err != nil {
+		return nil, err
+	}
+	if parser.pos < len(parser.source) {
+		return nil, parser.errorf("unexpected %q", parser.source[parser.pos:])
+	}
+	return &JSONPath{expression: expression, steps: steps}, nil
+}
+
+// QueryNested finds every value of data matching expression.
+func QueryNested(data any, expression string) ([]PathMatch, error) {
+	path, err := CompileJSONPath(expression)
+	if err != nil {
+		return nil, err
+	}
+	return path.Find(data), nil
+}
+
+func (p *JSONPath) String() string {
+	return p.expression
+}
+
+type pathNode struct {
+	path  string
+	value reflect.Value
+}
+
+// Find returns every match in document order, map keys sorted.
+func (p *JSONPath) Find(data any) []PathMatch {
+	root := reflect.ValueOf(data)
+	nodes := p.find(root, root)
+	matches := make([]PathMatch, 0, len(nodes))
+	for _, node := range nodes {
+		var value any
+		if node.value.IsValid() && node.value.CanInterface() {
+			value = node.value.Interface()
+		}
+		matches = append(matches, PathMatch{Path: node.path, Value: value})
+	}
+	return matches
+}
+
+// First returns the value of the first match.
+func (p *JSONPath) First(data any) (any, bool) {
+	matches := p.Find(data)
+	if len(matches) == 0 {
+		return nil, false
+	}
+	return matches[0].Value, true
+}
+
+func (p *JSONPath) find(root reflect.Value, current reflect.Value) []pathNode {
+	nodes := []pathNode{{path: "$", value: current}}
+	for _, step := range p.steps {
+		next := []pathNode{}
+		for _, node := range nodes {
+			candidates := []pathNode{node}
+			if step.recursive {
+				candidates = descendants(node, candidates, map[pointerVisit]bool{})
+			}
+			for _, candidate := range candidates {
+				for _, selector := range step.selectors {
+					next = append(next, selector.apply(root, candidate)...)
+				}
+			}
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+func derefPathValue(value reflect.Value) reflect.Value {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return reflect.Value{}
+		}
+		value = value.Elem()
+	}
+	return value
+}
+
+var pathIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func childPath(parent string, key string) string {
+	if pathIdentifier.MatchString(key) {
+		return parent + "." + key
+	}
+	return parent + "[" + strconv.Quote(key) + "]"
+}
+
+func indexPath(parent string, index int) string {
+	return parent + "[" + strconv.Itoa(index) + "]"
+}
+
+// pathFieldName is the name a struct field is matched by, "" to skip it.
+func pathFieldName(field reflect.StructField) string {
+	if !field.IsExported() || field.Anonymous {
+		return ""
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		name = field.Name
+	}
+	return name
+}
+
+// children lists the members of maps, structs and slices.
+func children(node pathNode) []pathNode {
+	value := derefPathValue(node.value)
+	result := []pathNode{}
+	switch value.Kind() {
+	case reflect.Map:
+		keys := value.MapKeys()
+		sort.Slice(keys, func(a, b int) bool {
+			return fmt.Sprint(keys[a].Interface()) < fmt.Sprint(keys[b].Interface())
+		})
+		for _, key := range keys {
+			result = append(result, pathNode{childPath(node.path, fmt.Sprint(key.Interface())), value.MapIndex(key)})
+		}
+	case reflect.Struct:
+		for _, field := range reflect.VisibleFields(value.Type()) {
+			name := pathFieldName(field)
+			if name == "" {
+				continue
+			}
+			if fieldValue, err := value.FieldByIndexErr(field.Index); err == nil {
+				result = append(result, pathNode{childPath(node.path, name), fieldValue})
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			result = append(result, pathNode{indexPath(node.path, i), value.Index(i)})
+		}
+	}
+	return result
+}
+
+// pointerVisit identifies a pointer, map or slice by its address and type,
+// a struct and its first field share an address but not a type.
+type pointerVisit struct {
+	pointer uintptr
+	typ     reflect.Type
+}
+
+// pathPointers returns the pointers, maps and slices value refers to.
+func pathPointers(value reflect.Value) []pointerVisit {
+	visits := []pointerVisit{}
+	for value.IsValid() {
+		switch value.Kind() {
+		case reflect.Interface:
+			if value.IsNil() {
+				return visits
+			}
+			value = value.Elem()
+			continue
+		case reflect.Pointer, reflect.Map, reflect.Slice:
+			if !value.IsNil() {
+				visits = append(visits, pointerVisit{value.Pointer(), value.Type()})
+			}
+		}
+		if value.Kind() != reflect.Pointer {
+			return visits
+		}
+		value = value.Elem()
+	}
+	return visits
+}
+
+// descendants appends every node below node. visiting holds the pointers on
+// the current path, a node pointing back to one of them is listed but not
+// walked again.
+func descendants(node pathNode, result []pathNode, visiting map[pointerVisit]bool) []pathNode {
+	visits := pathPointers(node.value)
+	for _, visit := range visits {
+		if visiting[visit] {
+			return result
+		}
+	}
+	for _, visit := range visits {
+		visiting[visit] = true
+		defer delete(visiting, visit)
+	}
+	for _, child := range children(node) {
+		result = append(result, child)
+		result = descendants(child, result, visiting)
+	}
+	return result
+}
+
+func selectName(node pathNode, name string) []pathNode {
+	value := derefPathValue(node.value)
+	switch value.Kind() {
+	case reflect.Map:
+		if value.Type().Key().Kind() == reflect.String {
+			item := value.MapIndex(reflect.ValueOf(name).Convert(value.Type().Key()))
+			if item.IsValid() {
+				return []pathNode{{childPath(node.path, name), item}}
+			}
+			return nil
+		}
+		for _, key := range value.MapKeys() {
+			if fmt.Sprint(key.Interface()) == name {
+				return []pathNode{{childPath(node.path, name), value.MapIndex(key)}}
+			}
+		}
+	case reflect.Struct:
+		fields := reflect.VisibleFields(value.Type())
+		for _, exact := range []bool{true, false} {
+			for _, field := range fields {
+				fieldName := pathFieldName(field)
+				if fieldName == "" || !(fieldName == name || field.Name == name || (!exact && strings.EqualFold(fieldName, name))) {
+					continue
+				}
+				if fieldValue, err := value.FieldByIndexErr(field.Index); err == nil {
+					return []pathNode{{childPath(node.path, fieldName), fieldValue}}
+				}
+				return nil
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		// dotted paths address slices as items.0
+		if index, err := strconv.Atoi(name); err == nil {
+			return selectIndex(node, index)
+		}
+	}
+	return nil
+}
+
+func selectIndex(node pathNode, index int) []pathNode {
+	value := derefPathValue(node.value)
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		if value.Kind() == reflect.Map && index >= 0 {
+			return selectName(node, strconv.Itoa(index))
+		}
+		return nil
+	}
+	if index < 0 {
+		index += value.Len()
+	}
+	if index < 0 || index >= value.Len() {
+		return nil
+	}
+	return []pathNode{{indexPath(node.path, index), value.Index(index)}}
+}
+
+func selectSlice(node pathNode, bounds [3]*int) []pathNode {
+	value := derefPathValue(node.value)
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return nil
+	}
+	length := value.Len()
+	step := 1
+	if bounds[2] != nil {
+		step = *bounds[2]
+	}
+	normalize := func(bound *int, fallback int) int {
+		if bound == nil {
+			return fallback
+		}
+		index := *bound
+		if index < 0 {
+			index += length
+		}
+		if step > 0 {
+			return max(0, min(index, length))
+		}
+		return max(-1, min(index, length-1))
+	}
+	result := []pathNode{}
+	if step > 0 {
+		for i := normalize(bounds[0], 0); i < normalize(bounds[1], length); i += step {
+			result = append(result, pathNode{indexPath(node.path, i), value.Index(i)})
+		}
+	} else {
+		for i := normalize(bounds[0], length-1); i > normalize(bounds[1], -1); i += step {
+			result = append(result, pathNode{indexPath(node.path, i), value.Index(i)})
+		}
+	}
+	return result
+}
+
+func (s pathSelector) apply(root reflect.Value, node pathNode) []pathNode {
+	switch s.kind {
+	case pathName:
+		return selectName(node, s.name)
+	case pathWildcard:
+		return children(node)
+	case pathIndex:
+		return selectIndex(node, s.index)
+	case pathSlice:
+		return selectSlice(node, s.slice)
+	}
+	result := []pathNode{}
+	for _, child := range children(node) {
+		if pathTruthy(s.filter.eval(root, child.value)) {
+			result = append(result, child)
+		}
+	}
+	return result
+}
+
+type pathParser struct {
+	source string
+	pos    int
+}
+
+func (p *pathParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w: %s at %d in %q", ErrInvalidJSONPath, fmt.Sprintf(format, args...), p.pos, p.source)
+}
+
+func (p *pathParser) peek() byte {
+	if p.pos < len(p.source) {
+		return p.source[p.pos]
+	}
+	return 0
+}
+
+func (p *pathParser) skipSpaces() {
+	for p.pos < len(p.source) && p.source[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *pathParser) parsePath() ([]pathStep, error) {
+	steps := []pathStep{}
+	if char := p.peek(); char == '$' || char == '@' {
+		p.pos++
+	} else if p.pos < len(p.source) && char != '.' && char != '[' {
+		// a bare dotted path starts with a name
+		steps = append(steps, pathStep{selectors: []pathSelector{p.parseName()}})
+	}
+	for p.pos < len(p.source) {
+		step := pathStep{}
+		switch p.peek() {
+		case '.':
+			p.pos++
+			if p.peek() == '.' {
+				p.pos++
+				step.recursive = true
+			}
+			if p.peek() == '[' {
+				selectors, err := p.parseBracket()
+				if err != nil {
+					return nil, err
+				}
+				step.selectors = selectors
+				break
+			}
+			if p.pos == len(p.source) || p.peek() == '.' {
+				return nil, p.errorf("missing name")
+			}
+			step.selectors = []pathSelector{p.parseName()}
+		case '[':
+			selectors, err := p.parseBracket()
+			if err != nil {
+				return nil, err
+			}
+			step.selectors = selectors
+		default:
+			return steps, nil
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// parseName reads a dotted name up to the next '.' or '['. Inside filters
+// names also end at spaces and operators.
+func (p *pathParser) parseName() pathSelector {
+	start := p.pos
+	for p.pos < len(p.source) && !strings.ContainsRune(".[]()!=<>&|~ ,", rune(p.source[p.pos])) {
+		p.pos++
+	}
+	name := p.source[start:p.pos]
+	if name == "*" {
+		return pathSelector{kind: pathWildcard}
+	}
+	return pathSelector{kind: pathName, name: name}
+}
+
+func (p *pathParser) parseString() (string, error) {
+	quote := p.source[p.pos]
+	var builder strings.Builder
+	for p.pos++; p.pos < len(p.source); p.pos++ {
+		char := p.source[p.pos]
+		if char == '\\' && p.pos+1 < len(p.source) {
+			p.pos++
+			builder.WriteByte(p.source[p.pos])
+			continue
+		}
+		if char == quote {
+			p.pos++
+			return builder.String(), nil
+		}
+		builder.WriteByte(char)
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *pathParser) parseInt() (*int, error) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	for p.pos < len(p.source) && p.source[p.pos] >= '0' && p.source[p.pos] <= '9' {
+		p.pos++
+	}
+	if start == p.pos {
+		return nil, nil
+	}
+	number, err := strconv.Atoi(p.source[start:p.pos])
+	if err != nil {
+		return nil, p.errorf("invalid index %q", p.source[start:p.pos])
+	}
+	return &number, nil
+}
+
+func (p *pathParser) parseBracket() ([]pathSelector, error) {
+	p.pos++
+	p.skipSpaces()
+	if strings.HasPrefix(p.source[p.pos:], "?(") {
+		p.pos += 2
+		filter, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+		if p.peek() != ')' {
+			return nil, p.errorf("missing ) of the filter")
+		}
+		p.pos++
+		p.skipSpaces()
+		if p.peek() != ']' {
+			return nil, p.errorf("missing ]")
+		}
+		p.pos++
+		return []pathSelector{{kind: pathFilter, filter: filter}}, nil
+	}
+
+	selectors := []pathSelector{}
+	for {
+		p.skipSpaces()
+		switch char := p.peek(); {
+		case char == '*':
+			p.pos++
+			selectors = append(selectors, pathSelector{kind: pathWildcard})
+		case char == '\'' || char == '"':
+			name, err := p.parseString()
+			if err != nil {
+				return nil, err
+			}
+			selectors = append(selectors, pathSelector{kind: pathName, name: name})
+		default:
+			var bounds [3]*int
+			part := 0
+			for {
+				number, err := p.parseInt()
+				if err != nil {
+					return nil, err
+				}
+				bounds[part] = number
+				p.skipSpaces()
+				if p.peek() != ':' || part == 2 {
+					break
+				}
+				p.pos++
+				part++
+				p.skipSpaces()
+			}
+			switch {
+			case part == 0 && bounds[0] != nil:
+				selectors = append(selectors, pathSelector{kind: pathIndex, index: *bounds[0]})
+			case part == 0:
+				return nil, p.errorf("invalid selector")
+			case bounds[2] != nil && *bounds[2] == 0:
+				return nil, p.errorf("slice step can not be zero")
+			default:
+				selectors = append(selectors, pathSelector{kind: pathSlice, slice: bounds})
+			}
+		}
+		p.skipSpaces()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return selectors, nil
+		default:
+			return nil, p.errorf("missing ]")
+		}
+	}
+}
+
+// pathFilterExpr is a node of a filter expression, eval returns
+// pathUndefined for paths without a match.
+type pathFilterExpr interface {
+	eval(root reflect.Value, current reflect.Value) any
+}
+
+type pathUndefinedValue struct{}
+
+var pathUndefined = pathUndefinedValue{}
+
+type pathLiteral struct{ value any }
+
+type pathQuery struct {
+	relative bool
+	path     *JSONPath
+}
+
+type pathNot struct{ operand pathFilterExpr }
+
+type pathBinary struct {
+	operator    string
+	left, right pathFilterExpr
+	pattern     *regexp.Regexp
+}
+
+func (l pathLiteral) eval(reflect.Value, reflect.Value) any {
+	return l.value
+}
+
+func (q pathQuery) eval(root reflect.Value, current reflect.Value) any {
+	start := root
+	if q.relative {
+		start = current
+	}
+	nodes := q.path.find(root, start)
+	if len(nodes) == 0 || !nodes[0].value.IsValid() {
+		return pathUndefined
+	}
+	value := derefPathValue(nodes[0].value)
+	if !value.IsValid() {
+		return nil
+	}
+	return value.Interface()
+}
+
+func (n pathNot) eval(root reflect.Value, current reflect.Value) any {
+	return !pathTruthy(n.operand.eval(root, current))
+}
+
+func (b pathBinary) eval(root reflect.Value, current reflect.Value) any {
+	left := b.left.eval(root, current)
+	switch b.operator {
+	case "&&":
+		return pathTruthy(left) && pathTruthy(b.right.eval(root, current))
+	case "||":
+		return pathTruthy(left) || pathTruthy(b.right.eval(root, current))
+	}
+	right := b.right.eval(root, current)
+	if left == pathUndefined || right == pathUndefined {
+		return b.operator == "!="
+	}
+	switch b.operator {
+	case "==":
+		return pathEqual(left, right)
+	case "!=":
+		return !pathEqual(left, right)
+	case "=~":
+		text, ok := left.(string)
+		return ok && b.pattern.MatchString(text)
+	}
+	if leftNumber, ok := pathNumber(left); ok {
+		if rightNumber, ok := pathNumber(right); ok {
+			return compareOrdered(b.operator, leftNumber, rightNumber)
+		}
+		return false
+	}
+	leftText, leftOk := left.(string)
+	rightText, rightOk := right.(string)
+	return leftOk && rightOk && compareOrdered(b.operator, leftText, rightText)
+}
+
+func compareOrdered[T float64 | string](operator string, left T, right T) bool {
+	switch operator {
+	case "<":
+		return left < right
+	case "<=":
+		return left <= right
+	case ">":
+		return left > right
+	}
+	return left >= right
+}
+
+func pathNumber(value any) (float64, bool) {
+	reflected := reflect.ValueOf(value)
+	switch reflected.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(reflected.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(reflected.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return reflected.Float(), true
+	}
+	return 0, false
+}
+
+func pathEqual(left any, right any) bool {
+	if leftNumber, ok := pathNumber(left); ok {
+		rightNumber, ok := pathNumber(right)
+		return ok && leftNumber == rightNumber
+	}
+	if leftText, ok := left.(string); ok {
+		rightText, ok := right.(string)
+		return ok && leftText == rightText
+	}
+	return reflect.DeepEqual(left, right)
+}
+
+// pathTruthy follows JavaScript, as the original JSONPath does: missing
+// paths, null, false, zero and "" are false.
+func pathTruthy(value any) bool {
+	switch v := value.(type) {
+	case pathUndefinedValue, nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	}
+	if number, ok := pathNumber(value); ok {
+		return number != 0
+	}
+	return true
+}
+
+func (p *pathParser) parseOr() (pathFilterExpr, error) {
+	left, err := p.parseAnd()
+	for err == nil {
+		p.skipSpaces()
+		if !strings.HasPrefix(p.source[p.pos:], "||") {
+			return left, nil
+		}
+		p.pos += 2
+		var right pathFilterExpr
+		right, err = p.parseAnd()
+		left = pathBinary{operator: "||", left: left, right: right}
+	}
+	return nil, err
+}
+
+func (p *pathParser) parseAnd() (pathFilterExpr, error) {
+	left, err := p.parseUnary()
+	for err == nil {
+		p.skipSpaces()
+		if !strings.HasPrefix(p.source[p.pos:], "&&") {
+			return left, nil
+		}
+		p.pos += 2
+		var right pathFilterExpr
+		right, err = p.parseUnary()
+		left = pathBinary{operator: "&&", left: left, right: right}
+	}
+	return nil, err
+}
+
+func (p *pathParser) parseUnary() (pathFilterExpr, error) {
+	p.skipSpaces()
+	if p.peek() == '!' && !strings.HasPrefix(p.source[p.pos:], "!=") {
+		p.pos++
+		operand, err := p.parseUnary()
+		return pathNot{operand: operand}, err
+	}
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	for _, operator := range []string{"==", "!=", "<=", ">=", "=~", "<", ">"} {
+		if !strings.HasPrefix(p.source[p.pos:], operator) {
+			continue
+		}
+		p.pos += len(operator)
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		binary := pathBinary{operator: operator, left: left, right: right}
+		if operator == "=~" {
+			literal, ok := right.(pathLiteral)
+			text, isText := literal.value.(string)
+			if !ok || !isText {
+				return nil, p.errorf("=~ needs a string pattern")
+			}
+			if binary.pattern, err = regexp.Compile(text); err != nil {
+				return nil, p.errorf("%v", err)
+			}
+		}
+		return binary, nil
+	}
+	return left, nil
+}
+
+func (p *pathParser) parseOperand() (pathFilterExpr, error) {
+	p.skipSpaces()
+	switch char := p.peek(); {
+	case char == '(':
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+		if p.peek() != ')' {
+			return nil, p.errorf("missing )")
+		}
+		p.pos++
+		return expr, nil
+	case char == '\'' || char == '"':
+		text, err := p.parseString()
+		return pathLiteral{value: text}, err
+	case char == '@' || char == '$':
+		steps, err := p.parsePath()
+		if err != nil {
+			return nil, err
+		}
+		return pathQuery{relative: char == '@', path: &JSONPath{steps: steps}}, nil
+	case char == '-' || (char >= '0' && char <= '9'):
+		start := p.pos
+		for p.pos < len(p.source) && strings.ContainsRune("0123456789.-+eE", rune(p.source[p.pos])) {
+			p.pos++
+		}
+		number, err := strconv.ParseFloat(p.source[start:p.pos], 64)
+		if err != nil {
+			return nil, p.errorf("invalid number %q", p.source[start:p.pos])
+		}
+		return pathLiteral{value: number}, nil
+	}
+	for word, value := range map[string]any{"true": true, "false": false, "null": nil} {
+		if strings.HasPrefix(p.source[p.pos:], word) {
+			p.pos += len(word)
+			return pathLiteral{value: value}, nil
+		}
+	}
+	return nil, p.errorf("unexpected %q", p.source[p.pos:])
+}
@@ -0,0 +1,107 @@
+package utils_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	utils "github.com/jingyuexing/go-utils"
+)
+
+func TestQueryNested(t *testing.T) {
+	type Person struct {
+		Name  string `json:"name"`
+		Age   int    `json:"age"`
+		Email string `json:"email,omitempty"`
+		Tags  []string
+	}
+	data := map[string]any{
+		"store": map[string]any{
+			"people": []Person{
+				{Name: "ann", Age: 17, Tags: []string{"a"}},
+				{Name: "bob", Age: 30, Email: "bob@example.com"},
+				{Name: "cat", Age: 42},
+			},
+			"owner": &Person{Name: "dan", Age: 50},
+		},
+		"items": []any{
+			map[string]any{"id": 1, "price": 9.5},
+			map[string]any{"id": 2, "price": 20},
+			map[string]any{"id": 3},
+		},
+		"odd key": "x",
+	}
+	cases := []struct {
+		expression, expected string
+	}{
+		{"items.0.id", "$.items[0].id=1"},
+		{"$.items[-1].id", "$.items[2].id=3"},
+		{"items.*.id", "$.items[0].id=1 $.items[1].id=2 $.items[2].id=3"},
+		{"$.items[0,2].id", "$.items[0].id=1 $.items[2].id=3"},
+		{"$.items[1:].id", "$.items[1].id=2 $.items[2].id=3"},
+		{"$.items[::-2].id", "$.items[2].id=3 $.items[0].id=1"},
+		{"$['odd key']", "$[\"odd key\"]=x"},
+		{"store.owner.name", "$.store.owner.name=dan"},
+		{"store.people[1].Name", "$.store.people[1].name=bob"},
+		{"$..age", "$.store.owner.age=50 $.store.people[0].age=17 $.store.people[1].age=30 $.store.people[2].age=42"},
+		{"$.store.people[?(@.age > 18)].name", "$.store.people[1].name=bob $.store.people[2].name=cat"},
+		{"$.store.people[?(@.email)].name", "$.store.people[1].name=bob"},
+		{"$.store.people[?(!@.email && @.age >= 18)].name", "$.store.people[2].name=cat"},
+		{"$.store.people[?(@.name == 'ann' || @.name =~ '^c')].age", "$.store.people[0].age=17 $.store.people[2].age=42"},
+		{"$.store.people[?(@.age < $.store.owner.age && @.Tags[0] == \"a\")].name", "$.store.people[0].name=ann"},
+		{"$.items[?(@.price >= 9.5)].id", "$.items[0].id=1 $.items[1].id=2"},
+		{"$..[?(@.id == 2)].price", "$.items[1].price=20"},
+		{"missing.path", ""},
+	}
+	for _, c := range cases {
+		matches, err := utils.QueryNested(data, c.expression)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		got := []string{}
+		for _, match := range matches {
+			got = append(got, fmt.Sprintf("%s=%v", match.Path, match.Value))
+		}
+		if strings.Join(got, " ") != c.expected {
+			t.Error(fmt.Sprintf("Expect: %s, but got %s (%s)", c.expected, strings.Join(got, " "), c.expression))
+		}
+	}
+
+	for _, expression := range []string{"$.items[", "$.items[?(@.id ==)]", "$.a[::0]", "$.a..", "$.a[?(@.a =~ '(')]"} {
+		if _, err := utils.CompileJSONPath(expression); !errors.Is(err, utils.ErrInvalidJSONPath) {
+			t.Error(fmt.Sprintf("Expect: %v, but got %v (%s)", utils.ErrInvalidJSONPath, err, expression))
+		}
+	}
+	type link struct {
+		Name string `json:"name"`
+		Next *link  `json:"next"`
+	}
+	chain := link{Name: "a"}
+	chain.Next = &chain
+	loop := map[string]any{"name": "b"}
+	loop["self"] = loop
+	for _, c := range []struct {
+		data     any
+		expected string
+	}{
+		{chain, "$.name=a $.next.name=a $.next.next.name=a"},
+		{&chain, "$.name=a $.next.name=a"},
+		{loop, "$.name=b $.self.name=b"},
+	} {
+		matches, err := utils.QueryNested(c.data, "$..name")
+		got := []string{}
+		for _, match := range matches {
+			got = append(got, fmt.Sprintf("%s=%v", match.Path, match.Value))
+		}
+		if err != nil || strings.Join(got, " ") != c.expected {
+			t.Error(fmt.Sprintf("Expect: %s, but got %s %v", c.expected, strings.Join(got, " "), err))
+		}
+	}
+
+	path, _ := utils.CompileJSONPath("$.store.owner.age")
+	if value, ok := path.First(data); !ok || value != 50 {
+		t.Error(fmt.Sprintf("Expect: %d, but got %v", 50, value))
+	}
+}
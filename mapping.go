@@ -25,12 +25,7 @@ type KeyFunc func(field reflect.StructField) string
 //
 //	Omit(p,"Name")
 func Omit[T any](target T, fields ...string) map[string]any {
-	mapping := map[string]bool{}
-	for _, value := range fields {
-		mapping[SnakeCase(value)] = true
-		mapping[value] = true
-		mapping[value] = true
-	}
+	mapping := fieldKeySet(fields)
 	result := StructFilter(target, func(field string, val reflect.Value) bool {
 		_, ok := mapping[field]
 		return !ok
@@ -54,10 +49,7 @@ func Omit[T any](target T, fields ...string) map[string]any {
 //
 //	Pick(p,[]string{"Name"})
 func Pick[T any](target T, fields ...string) map[string]any {
-	mapping := map[string]bool{}
-	for _, value := range fields {
-		mapping[value] = true
-	}
+	mapping := fieldKeySet(fields)
 	result := StructFilter(target, func(field string, val reflect.Value) bool {
 		_, ok := mapping[field]
 		return ok
@@ -65,6 +57,17 @@ func Pick[T any](target T, fields ...string) map[string]any {
 	return result
 }
 
+// fieldKeySet matches fields by their key or by a Go field name, which
+// StructFilter turns into snake case.
+func fieldKeySet(fields []string) map[string]bool {
+	mapping := map[string]bool{}
+	for _, value := range fields {
+		mapping[value] = true
+		mapping[SnakeCase(value)] = true
+	}
+	return mapping
+}
+
 func jsonKeyFunc(field reflect.StructField) string {
 	if jsonTag := field.Tag.Get("json"); jsonTag != "" && jsonTag != "-" {
 		return jsonTag
@@ -0,0 +1,586 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrNestedPath = errors.New("unreachable nested path")
+	ErrMergeType  = errors.New("incompatible merge types")
+)
+
+// nestedField finds the field of a struct called key, by field name, then
+// by json tag or GetFieldName, then ignoring case.
+func nestedField(value reflect.Value, key string) reflect.Value {
+	if field, ok := value.Type().FieldByName(key); ok && field.IsExported() {
+		return value.FieldByIndex(field.Index)
+	}
+	fields := reflect.VisibleFields(value.Type())
+	for _, exact := range []bool{true, false} {
+		for _, field := range fields {
+			if !field.IsExported() || field.Anonymous {
+				continue
+			}
+			tag, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if (exact && (tag == key || GetFieldName(field) == key)) ||
+				(!exact && (strings.EqualFold(tag, key) || strings.EqualFold(field.Name, key))) {
+				if fieldValue, err := value.FieldByIndexErr(field.Index); err == nil {
+					return fieldValue
+				}
+				return reflect.Value{}
+			}
+		}
+	}
+	return reflect.Value{}
+}
+
+// nestedMapKey converts a path key to the key type of a map.
+func nestedMapKey(key string, keyType reflect.Type) (reflect.Value, error) {
+	switch keyType.Kind() {
+	case reflect.String:
+		return reflect.ValueOf(key).Convert(keyType), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		number, err := strconv.ParseInt(key, 10, keyType.Bits())
+		return reflect.ValueOf(number).Convert(keyType), err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		number, err := strconv.ParseUint(key, 10, keyType.Bits())
+		return reflect.ValueOf(number).Convert(keyType), err
+	}
+	return reflect.Value{}, fmt.Errorf("%w: map key %s", ErrNestedPath, keyType)
+}
+
+// nestedIndex resolves a slice index, negative indexes count from the end.
+func nestedIndex(key string, length int) (int, error) {
+	index, err := strconv.Atoi(key)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q is not an index", ErrNestedPath, key)
+	}
+	if index < 0 {
+		index += length
+	}
+	if index < 0 {
+		return 0, fmt.Errorf("%w: index %s out of range", ErrNestedPath, key)
+	}
+	return index, nil
+}
+
+// assignable converts value to typ, a nil value becomes the zero value.
+func assignable(value reflect.Value, typ reflect.Type) (reflect.Value, error) {
+	if !value.IsValid() {
+		return reflect.Zero(typ), nil
+	}
+	if value.Type().AssignableTo(typ) {
+		return value, nil
+	}
+	if value.Type().ConvertibleTo(typ) && value.Kind() != reflect.String && typ.Kind() != reflect.String {
+		return value.Convert(typ), nil
+	}
+	return reflect.Value{}, fmt.Errorf("%w: can not use %s as %s", ErrMergeType, value.Type(), typ)
+}
+
+// convertNested converts value to typ like assignable, and also fills
+// structs from maps and typed slices from []any.
+func convertNested(value reflect.Value, typ reflect.Type) (reflect.Value, error) {
+	converted, err := assignable(value, typ)
+	if err == nil || !isMergeContainer(derefPathValue(value)) {
+		return converted, err
+	}
+	holder := reflect.New(typ).Elem()
+	if mergeErr := mergeValue(holder, value, MergeOptions{}); mergeErr != nil {
+		return converted, err
+	}
+	return holder, nil
+}
+
+// setNestedValue returns current, of type typ, with value set at keys.
+// Missing containers are created, maps for interface values.
+func setNestedValue(current reflect.Value, typ reflect.Type, keys []string, value reflect.Value) (reflect.Value, error) {
+	if len(keys) == 0 {
+		return convertNested(value, typ)
+	}
+	key, rest := keys[0], keys[1:]
+	switch typ.Kind() {
+	case reflect.Pointer:
+		if !current.IsValid() || current.IsNil() {
+			current = reflect.New(typ.Elem())
+		}
+		updated, err := setNestedValue(current.Elem(), typ.Elem(), keys, value)
+		if err != nil {
+			return current, err
+		}
+		current.Elem().Set(updated)
+		return current, nil
+	case reflect.Interface:
+		var inner reflect.Value
+		innerType := reflect.TypeOf(map[string]any{})
+		if current.IsValid() && !current.IsNil() {
+			inner, innerType = current.Elem(), current.Elem().Type()
+		}
+		updated, err := setNestedValue(inner, innerType, keys, value)
+		if err != nil {
+			return current, err
+		}
+		result := reflect.New(typ).Elem()
+		result.Set(updated)
+		return result, nil
+	case reflect.Map:
+		if !current.IsValid() || current.IsNil() {
+			current = reflect.MakeMap(typ)
+		}
+		mapKey, err := nestedMapKey(key, typ.Key())
+		if err != nil {
+			return current, err
+		}
+		updated, err := setNestedValue(current.MapIndex(mapKey), typ.Elem(), rest, value)
+		if err != nil {
+			return current, err
+		}
+		current.SetMapIndex(mapKey, updated)
+		return current, nil
+	case reflect.Struct:
+		copied := reflect.New(typ).Elem()
+		if current.IsValid() {
+			copied.Set(current)
+		}
+		field := nestedField(copied, key)
+		if !field.IsValid() || !field.CanSet() {
+			return current, fmt.Errorf("%w: %s has no field %q", ErrNestedPath, typ, key)
+		}
+		updated, err := setNestedValue(field, field.Type(), rest, value)
+		if err != nil {
+			return current, err
+		}
+		field.Set(updated)
+		return copied, nil
+	case reflect.Slice:
+		if !current.IsValid() {
+			current = reflect.MakeSlice(typ, 0, 0)
+		}
+		index, err := nestedIndex(key, current.Len())
+		if err != nil {
+			return current, err
+		}
+		if index >= current.Len() {
+			current = reflect.AppendSlice(current, reflect.MakeSlice(typ, index+1-current.Len(), index+1-current.Len()))
+		}
+		updated, err := setNestedValue(current.Index(index), typ.Elem(), rest, value)
+		if err != nil {
+			return current, err
+		}
+		current.Index(index).Set(updated)
+		return current, nil
+	case reflect.Array:
+		copied := reflect.New(typ).Elem()
+		if current.IsValid() {
+			copied.Set(current)
+		}
+		index, err := nestedIndex(key, typ.Len())
+		if err != nil || index >= typ.Len() {
+			return current, fmt.Errorf("%w: index %s out of range", ErrNestedPath, key)
+		}
+		updated, err := setNestedValue(copied.Index(index), typ.Elem(), rest, value)
+		if err != nil {
+			return current, err
+		}
+		copied.Index(index).Set(updated)
+		return copied, nil
+	}
+	return current, fmt.Errorf("%w: can not set %q on %s", ErrNestedPath, key, typ)
+}
+
+// nestedRoot returns the settable value behind a pointer target, maps can
+// also be passed directly.
+func nestedRoot(target any) (reflect.Value, error) {
+	value := reflect.ValueOf(target)
+	switch {
+	case value.Kind() == reflect.Pointer && !value.IsNil():
+		return value.Elem(), nil
+	case value.Kind() == reflect.Map && !value.IsNil():
+		return value, nil
+	}
+	return reflect.Value{}, fmt.Errorf("%w: need a pointer or a map, got %T", ErrNestedPath, target)
+}
+
+// SetNested sets value at the dotted path in target, a pointer or a map.
+// Missing maps, pointers and slice items on the way are created.
+//
+//	SetNested(&config, "server.ports.0", 8080)
+func SetNested(target any, path string, value any) error {
+	root, err := nestedRoot(target)
+	if err != nil {
+		return err
+	}
+	updated, err := setNestedValue(root, root.Type(), strings.Split(path, "."), reflect.ValueOf(value))
+	if err != nil {
+		return err
+	}
+	if root.CanSet() {
+		root.Set(updated)
+	}
+	return nil
+}
+
+func deleteNestedValue(current reflect.Value, keys []string) (reflect.Value, error) {
+	key, rest := keys[0], keys[1:]
+	missing := fmt.Errorf("%w: %q not found", ErrNestedPath, key)
+	switch current.Kind() {
+	case reflect.Pointer:
+		if current.IsNil() {
+			return current, missing
+		}
+		updated, err := deleteNestedValue(current.Elem(), keys)
+		if err == nil {
+			current.Elem().Set(updated)
+		}
+		return current, err
+	case reflect.Interface:
+		if current.IsNil() {
+			return current, missing
+		}
+		updated, err := deleteNestedValue(current.Elem(), keys)
+		if err != nil {
+			return current, err
+		}
+		result := reflect.New(current.Type()).Elem()
+		result.Set(updated)
+		return result, nil
+	case reflect.Map:
+		mapKey, err := nestedMapKey(key, current.Type().Key())
+		if err != nil || !current.MapIndex(mapKey).IsValid() {
+			return current, missing
+		}
+		if len(rest) == 0 {
+			current.SetMapIndex(mapKey, reflect.Value{})
+			return current, nil
+		}
+		updated, err := deleteNestedValue(current.MapIndex(mapKey), rest)
+		if err == nil {
+			current.SetMapIndex(mapKey, updated)
+		}
+		return current, err
+	case reflect.Struct:
+		copied := reflect.New(current.Type()).Elem()
+		copied.Set(current)
+		field := nestedField(copied, key)
+		if !field.IsValid() || !field.CanSet() {
+			return current, missing
+		}
+		if len(rest) == 0 {
+			field.Set(reflect.Zero(field.Type()))
+			return copied, nil
+		}
+		updated, err := deleteNestedValue(field, rest)
+		if err != nil {
+			return current, err
+		}
+		field.Set(updated)
+		return copied, nil
+	case reflect.Slice, reflect.Array:
+		index, err := nestedIndex(key, current.Len())
+		if err != nil || index >= current.Len() {
+			return current, missing
+		}
+		copied := current
+		if current.Kind() == reflect.Array {
+			copied = reflect.New(current.Type()).Elem()
+			copied.Set(current)
+		}
+		if len(rest) == 0 {
+			if current.Kind() == reflect.Array {
+				copied.Index(index).Set(reflect.Zero(current.Type().Elem()))
+				return copied, nil
+			}
+			// removes the item, the backing array is not modified
+			result := reflect.MakeSlice(current.Type(), 0, current.Len()-1)
+			result = reflect.AppendSlice(result, current.Slice(0, index))
+			return reflect.AppendSlice(result, current.Slice(index+1, current.Len())), nil
+		}
+		updated, err := deleteNestedValue(copied.Index(index), rest)
+		if err != nil {
+			return current, err
+		}
+		copied.Index(index).Set(updated)
+		return copied, nil
+	}
+	return current, missing
+}
+
+// DeleteNested removes the value at the dotted path: map keys are deleted,
+// slice items removed and struct fields reset to their zero value.
+func DeleteNested(target any, path string) error {
+	root, err := nestedRoot(target)
+	if err != nil {
+		return err
+	}
+	updated, err := deleteNestedValue(root, strings.Split(path, "."))
+	if err != nil {
+		return err
+	}
+	if root.CanSet() {
+		root.Set(updated)
+	}
+	return nil
+}
+
+// HasNested reports whether the dotted path exists in target.
+func HasNested(target any, path string) bool {
+	_, ok := lookupNested(target, strings.Split(path, "."))
+	return ok
+}
+
+// SliceMergeStrategy is how DeepMerge combines two slices.
+type SliceMergeStrategy int
+
+const (
+	// MergeSliceReplace replaces the destination slice
+	MergeSliceReplace SliceMergeStrategy = iota
+	// MergeSliceAppend appends the source items
+	MergeSliceAppend
+	// MergeSliceByIndex merges items with the same index
+	MergeSliceByIndex
+)
+
+type MergeOptions struct {
+	Slices SliceMergeStrategy
+	// KeepExisting keeps the non zero values of the destination.
+	KeepExisting bool
+}
+
+func WithSliceStrategy(strategy SliceMergeStrategy) DataOption[MergeOptions] {
+	return func(o *MergeOptions) {
+		o.Slices = strategy
+	}
+}
+
+func WithMergeKeepExisting() DataOption[MergeOptions] {
+	return func(o *MergeOptions) {
+		o.KeepExisting = true
+	}
+}
+
+// cloneValue copies maps, slices and pointers so that the merged result
+// does not share them with the source.
+func cloneValue(value reflect.Value) reflect.Value {
+	switch value.Kind() {
+	case reflect.Pointer:
+		if value.IsNil() {
+			return value
+		}
+		cloned := reflect.New(value.Type().Elem())
+		cloned.Elem().Set(cloneValue(value.Elem()))
+		return cloned
+	case reflect.Interface:
+		if value.IsNil() {
+			return value
+		}
+		cloned := reflect.New(value.Type()).Elem()
+		cloned.Set(cloneValue(value.Elem()))
+		return cloned
+	case reflect.Map:
+		if value.IsNil() {
+			return value
+		}
+		cloned := reflect.MakeMapWithSize(value.Type(), value.Len())
+		iter := value.MapRange()
+		for iter.Next() {
+			cloned.SetMapIndex(iter.Key(), cloneValue(iter.Value()))
+		}
+		return cloned
+	case reflect.Slice:
+		if value.IsNil() {
+			return value
+		}
+		cloned := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+		for i := 0; i < value.Len(); i++ {
+			cloned.Index(i).Set(cloneValue(value.Index(i)))
+		}
+		return cloned
+	case reflect.Struct:
+		cloned := reflect.New(value.Type()).Elem()
+		cloned.Set(value)
+		for i := 0; i < value.NumField(); i++ {
+			if cloned.Field(i).CanSet() {
+				cloned.Field(i).Set(cloneValue(value.Field(i)))
+			}
+		}
+		return cloned
+	}
+	return value
+}
+
+func mergeValue(dst reflect.Value, src reflect.Value, options MergeOptions) error {
+	for src.Kind() == reflect.Interface {
+		if src.IsNil() {
+			return nil
+		}
+		src = src.Elem()
+	}
+	if !src.IsValid() {
+		return nil
+	}
+	existing := dst
+	for existing.Kind() == reflect.Interface && !existing.IsNil() {
+		existing = existing.Elem()
+	}
+	if options.KeepExisting && !existing.IsZero() && !isMergeContainer(existing) {
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.Pointer:
+		if src.Kind() == reflect.Pointer {
+			if src.IsNil() {
+				return nil
+			}
+			src = src.Elem()
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return mergeValue(dst.Elem(), src, options)
+	case reflect.Interface:
+		if !dst.IsNil() {
+			inner := dst.Elem()
+			if inner.Type() == src.Type() && isMergeContainer(inner) {
+				copied := reflect.New(inner.Type()).Elem()
+				copied.Set(inner)
+				if err := mergeValue(copied, src, options); err != nil {
+					return err
+				}
+				dst.Set(copied)
+				return nil
+			}
+		}
+		value, err := assignable(cloneValue(src), dst.Type())
+		if err == nil {
+			dst.Set(value)
+		}
+		return err
+	case reflect.Map:
+		if src.Kind() == reflect.Pointer {
+			return mergeValue(dst, src.Elem(), options)
+		}
+		if src.Kind() != reflect.Map {
+			return fmt.Errorf("%w: %s into %s", ErrMergeType, src.Type(), dst.Type())
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMap(dst.Type()))
+		}
+		iter := src.MapRange()
+		for iter.Next() {
+			key, err := assignable(iter.Key(), dst.Type().Key())
+			if err != nil {
+				return err
+			}
+			item := reflect.New(dst.Type().Elem()).Elem()
+			if existing := dst.MapIndex(key); existing.IsValid() {
+				item.Set(existing)
+			}
+			if err := mergeValue(item, iter.Value(), options); err != nil {
+				return err
+			}
+			dst.SetMapIndex(key, item)
+		}
+		return nil
+	case reflect.Struct:
+		if src.Kind() == reflect.Pointer {
+			if src.IsNil() {
+				return nil
+			}
+			src = src.Elem()
+		}
+		switch {
+		case src.Type() == dst.Type():
+			for i := 0; i < dst.NumField(); i++ {
+				// zero fields of the source are treated as unset
+				if !dst.Field(i).CanSet() || src.Field(i).IsZero() {
+					continue
+				}
+				if err := mergeValue(dst.Field(i), src.Field(i), options); err != nil {
+					return err
+				}
+			}
+			return nil
+		case src.Kind() == reflect.Map && src.Type().Key().Kind() == reflect.String:
+			iter := src.MapRange()
+			for iter.Next() {
+				field := nestedField(dst, iter.Key().String())
+				if !field.IsValid() || !field.CanSet() {
+					continue
+				}
+				if err := mergeValue(field, iter.Value(), options); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return fmt.Errorf("%w: %s into %s", ErrMergeType, src.Type(), dst.Type())
+	case reflect.Slice:
+		if src.Kind() != reflect.Slice && src.Kind() != reflect.Array {
+			return fmt.Errorf("%w: %s into %s", ErrMergeType, src.Type(), dst.Type())
+		}
+		items := reflect.MakeSlice(dst.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			if err := mergeValue(items.Index(i), src.Index(i), options); err != nil {
+				return err
+			}
+		}
+		switch options.Slices {
+		case MergeSliceAppend:
+			dst.Set(reflect.AppendSlice(cloneValue(dst), items))
+		case MergeSliceByIndex:
+			merged := cloneValue(dst)
+			for i := 0; i < src.Len(); i++ {
+				if i >= merged.Len() {
+					merged = reflect.Append(merged, items.Index(i))
+					continue
+				}
+				if err := mergeValue(merged.Index(i), src.Index(i), options); err != nil {
+					return err
+				}
+			}
+			dst.Set(merged)
+		default:
+			dst.Set(items)
+		}
+		return nil
+	}
+	value, err := assignable(src, dst.Type())
+	if err == nil {
+		dst.Set(value)
+	}
+	return err
+}
+
+func isMergeContainer(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Map, reflect.Struct, reflect.Slice, reflect.Pointer, reflect.Interface:
+		return true
+	}
+	return false
+}
+
+// DeepMerge merges src into dst, a pointer or a map. Maps and structs are
+// merged key by key, pointers are followed and zero struct fields of src
+// are skipped. Slices are replaced unless another strategy is chosen.
+func DeepMerge(dst any, src any, opts ...DataOption[MergeOptions]) error {
+	options := MergeOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	root, err := nestedRoot(dst)
+	if err != nil {
+		return err
+	}
+	if root.Kind() == reflect.Map && !root.CanSet() {
+		// a map passed by value can be filled but not replaced
+		copied := reflect.New(root.Type()).Elem()
+		copied.Set(root)
+		return mergeValue(copied, reflect.ValueOf(src), options)
+	}
+	return mergeValue(root, reflect.ValueOf(src), options)
+}
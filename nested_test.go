@@ -0,0 +1,170 @@
+package utils_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	utils "github.com/jingyuexing/go-utils"
+)
+
+type nestedServer struct {
+	Host  string `json:"host"`
+	Ports []int  `json:"ports"`
+	TLS   *struct {
+		Cert string
+	}
+}
+
+type nestedConfig struct {
+	Name    string
+	Server  nestedServer `json:"server"`
+	Labels  map[string]string
+	Extra   any
+	Backups []nestedServer
+}
+
+func TestSetNested(t *testing.T) {
+	config := nestedConfig{}
+	steps := []struct {
+		path  string
+		value any
+	}{
+		{"Name", "app"},
+		{"server.host", "localhost"},
+		{"server.ports.1", 8080},
+		{"server.TLS.Cert", "a.pem"},
+		{"Labels.env", "dev"},
+		{"Extra.deep.list", []int{1}},
+		{"Backups.0.host", "backup"},
+	}
+	for _, step := range steps {
+		if err := utils.SetNested(&config, step.path, step.value); err != nil {
+			t.Error(err)
+		}
+	}
+	expected := `{app {localhost [0 8080] a.pem} map[env:dev] map[deep:map[list:[1]]] [{backup [] }]}`
+	got := fmt.Sprintf("{%s {%s %v %s} %v %v [{%s %v }]}", config.Name, config.Server.Host, config.Server.Ports,
+		config.Server.TLS.Cert, config.Labels, config.Extra, config.Backups[0].Host, config.Backups[0].Ports)
+	if got != expected {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s", expected, got))
+	}
+	if err := utils.SetNested(&config, "server.missing", 1); !errors.Is(err, utils.ErrNestedPath) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrNestedPath, err))
+	}
+	if err := utils.SetNested(&config, "Name", 1); !errors.Is(err, utils.ErrMergeType) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrMergeType, err))
+	}
+	if err := utils.SetNested(config, "Name", "x"); !errors.Is(err, utils.ErrNestedPath) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrNestedPath, err))
+	}
+
+	data := map[string]any{"list": []any{1, 2, 3}}
+	utils.SetNested(data, "list.-1", 30)
+	utils.SetNested(data, "a.b.c", true)
+	if !reflect.DeepEqual(data, map[string]any{"list": []any{1, 2, 30}, "a": map[string]any{"b": map[string]any{"c": true}}}) {
+		t.Error(fmt.Sprintf("Expect: list ending with 30 and a.b.c, but got %v", data))
+	}
+	if got := utils.CreateNestedObject("a/b", 1, "/"); !reflect.DeepEqual(got, map[string]any{"a": map[string]any{"b": 1}}) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", map[string]any{"a": map[string]any{"b": 1}}, got))
+	}
+	number := 2
+	if got := utils.CreateNestedObject("a.b", &number, "."); !reflect.DeepEqual(got, map[string]any{"a": map[string]any{"b": 2}}) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", map[string]any{"a": map[string]any{"b": 2}}, got))
+	}
+	if got := utils.CreateNestedObject("a", (*int)(nil), "."); !reflect.DeepEqual(got, map[string]any{"a": nil}) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", map[string]any{"a": nil}, got))
+	}
+}
+
+func TestDeleteNested(t *testing.T) {
+	config := nestedConfig{Name: "app", Server: nestedServer{Host: "h", Ports: []int{1, 2, 3}}, Labels: map[string]string{"a": "1"}}
+	for _, path := range []string{"server.ports.1", "Labels.a", "Name"} {
+		if !utils.HasNested(&config, path) {
+			t.Error(fmt.Sprintf("Expect: %s to exist", path))
+		}
+		if err := utils.DeleteNested(&config, path); err != nil {
+			t.Error(err)
+		}
+	}
+	if !reflect.DeepEqual(config.Server.Ports, []int{1, 3}) || len(config.Labels) != 0 || config.Name != "" {
+		t.Error(fmt.Sprintf("Expect: deleted values, but got %+v", config))
+	}
+	if utils.HasNested(&config, "Labels.a") || utils.HasNested(&config, "server.ports.5") {
+		t.Error("Expect: deleted paths to be missing")
+	}
+	numbers := map[int]string{1: "a"}
+	if err := utils.SetNested(numbers, "2", "b"); err != nil || !utils.HasNested(numbers, "2") || !utils.HasNested(&config, "server.ports.-1") {
+		t.Error(fmt.Sprintf("Expect: the paths SetNested accepts to exist, but got %v %v", numbers, err))
+	}
+	if err := utils.DeleteNested(&config, "Labels.a"); !errors.Is(err, utils.ErrNestedPath) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrNestedPath, err))
+	}
+	data := map[string]any{"a": map[string]any{"b": []any{"x", "y"}}}
+	utils.DeleteNested(data, "a.b.0")
+	if !reflect.DeepEqual(data, map[string]any{"a": map[string]any{"b": []any{"y"}}}) {
+		t.Error(fmt.Sprintf("Expect: a.b to be [y], but got %v", data))
+	}
+}
+
+func TestDeepMerge(t *testing.T) {
+	base := func() map[string]any {
+		return map[string]any{
+			"name": "app",
+			"server": map[string]any{
+				"host":  "localhost",
+				"ports": []any{80, 443},
+			},
+			"items": []any{map[string]any{"id": 1, "tag": "a"}},
+		}
+	}
+	override := map[string]any{
+		"server": map[string]any{"ports": []any{8080}, "debug": true},
+		"items":  []any{map[string]any{"tag": "b"}, map[string]any{"id": 2}},
+	}
+	cases := []struct {
+		strategy utils.SliceMergeStrategy
+		ports    []any
+		items    []any
+	}{
+		{utils.MergeSliceReplace, []any{8080}, []any{map[string]any{"tag": "b"}, map[string]any{"id": 2}}},
+		{utils.MergeSliceAppend, []any{80, 443, 8080}, []any{map[string]any{"id": 1, "tag": "a"}, map[string]any{"tag": "b"}, map[string]any{"id": 2}}},
+		{utils.MergeSliceByIndex, []any{8080, 443}, []any{map[string]any{"id": 1, "tag": "b"}, map[string]any{"id": 2}}},
+	}
+	for _, c := range cases {
+		dst := base()
+		if err := utils.DeepMerge(dst, override, utils.WithSliceStrategy(c.strategy)); err != nil {
+			t.Error(err)
+			continue
+		}
+		expected := map[string]any{
+			"name":   "app",
+			"server": map[string]any{"host": "localhost", "ports": c.ports, "debug": true},
+			"items":  c.items,
+		}
+		if !reflect.DeepEqual(dst, expected) {
+			t.Error(fmt.Sprintf("Expect: %v, but got %v", expected, dst))
+		}
+	}
+
+	config := nestedConfig{Name: "app", Server: nestedServer{Host: "a", Ports: []int{1}}, Extra: map[string]any{"x": 1}}
+	patch := &nestedConfig{Server: nestedServer{Ports: []int{2}, TLS: &struct{ Cert string }{"b.pem"}}, Extra: map[string]any{"y": 2}}
+	if err := utils.DeepMerge(&config, patch, utils.WithSliceStrategy(utils.MergeSliceAppend)); err != nil {
+		t.Fatal(err)
+	}
+	if config.Name != "app" || config.Server.Host != "a" || !reflect.DeepEqual(config.Server.Ports, []int{1, 2}) ||
+		config.Server.TLS == patch.Server.TLS || config.Server.TLS.Cert != "b.pem" ||
+		!reflect.DeepEqual(config.Extra, map[string]any{"x": 1, "y": 2}) {
+		t.Error(fmt.Sprintf("Expect: merged config, but got %+v", config))
+	}
+
+	kept := map[string]any{"a": 1, "b": map[string]any{"c": ""}}
+	utils.DeepMerge(kept, map[string]any{"a": 2, "b": map[string]any{"c": "x"}, "d": 3}, utils.WithMergeKeepExisting())
+	if !reflect.DeepEqual(kept, map[string]any{"a": 1, "b": map[string]any{"c": "x"}, "d": 3}) {
+		t.Error(fmt.Sprintf("Expect: existing values kept, but got %v", kept))
+	}
+	if err := utils.DeepMerge(&config, map[string]any{"Name": 1}); !errors.Is(err, utils.ErrMergeType) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrMergeType, err))
+	}
+}
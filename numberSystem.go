@@ -2,6 +2,7 @@ package utils
 
 import (
 	"math"
+	"strconv"
 	"strings"
 )
 
@@ -58,3 +59,88 @@ func removeTrailingZero(str string) string {
     }
 	return str
 }
+
+// ChineseCountFormat configures FormatChineseCount.
+type ChineseCountFormat struct {
+	// Precision is the maximum number of decimals, trailing zeros are dropped.
+	Precision int
+	// Numerals writes the number with Chinese numerals (三点五万).
+	Numerals bool
+	// Upper uses the financial numerals (叁点伍万), it implies Numerals.
+	Upper bool
+}
+
+func WithCountPrecision(precision int) DataOption[ChineseCountFormat] {
+	return func(f *ChineseCountFormat) {
+		f.Precision = precision
+	}
+}
+
+func WithChineseNumerals(upper bool) DataOption[ChineseCountFormat] {
+	return func(f *ChineseCountFormat) {
+		f.Numerals = true
+		f.Upper = upper
+	}
+}
+
+var chineseCountMagnitudes = []struct {
+	unit   string
+	factor float64
+}{
+	{"", 1}, {"万", 1e4}, {"亿", 1e8}, {"兆", 1e12},
+}
+
+// FormatChineseCount renders a count with the largest of 万, 亿 and 兆 that
+// keeps the number at or above 1, e.g. 35000 is "3.5万" and 120000000 is
+// "1.2亿". Counts below 10000 have no unit.
+func FormatChineseCount(value float64, opts ...DataOption[ChineseCountFormat]) string {
+	format := ChineseCountFormat{Precision: 2}
+	for _, opt := range opts {
+		opt(&format)
+	}
+	precision := max(format.Precision, 0)
+	rounding := math.Pow(10, float64(precision))
+	round := func(v float64) float64 {
+		return math.Round(v*rounding) / rounding
+	}
+
+	sign := ""
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+	index := 0
+	for i, magnitude := range chineseCountMagnitudes {
+		if value >= magnitude.factor {
+			index = i
+		}
+	}
+	// 99999999 is "1亿" rather than "10000万"
+	if next := index + 1; next < len(chineseCountMagnitudes) &&
+		round(value/chineseCountMagnitudes[index].factor) >= chineseCountMagnitudes[next].factor/chineseCountMagnitudes[index].factor {
+		index = next
+	}
+	unit := chineseCountMagnitudes[index].unit
+	value /= chineseCountMagnitudes[index].factor
+
+	number := strconv.FormatFloat(round(value), 'f', precision, 64)
+	if strings.Contains(number, ".") {
+		number = strings.TrimRight(strings.TrimRight(number, "0"), ".")
+	}
+	if !format.Numerals && !format.Upper {
+		return sign + number + unit
+	}
+	integer, decimals, _ := strings.Cut(number, ".")
+	whole, _ := strconv.ParseInt(integer, 10, 64)
+	result := ToChineseNumber(whole, 10, format.Upper)
+	if decimals != "" {
+		result += "点"
+		for _, digit := range decimals {
+			result += ToChineseNumber(int64(digit-'0'), 10, format.Upper)
+		}
+	}
+	if sign != "" {
+		result = "负" + result
+	}
+	return result + unit
+}
@@ -0,0 +1,589 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidQuery = errors.New("invalid query")
+
+// QueryArrayFormat is how EncodeQuery writes slices.
+type QueryArrayFormat int
+
+const (
+	// QueryArrayBrackets writes a[]=1&a[]=2
+	QueryArrayBrackets QueryArrayFormat = iota
+	// QueryArrayIndices writes a[0]=1&a[1]=2
+	QueryArrayIndices
+	// QueryArrayComma writes a=1,2 and splits comma separated values when decoding
+	QueryArrayComma
+	// QueryArrayRepeat writes a=1&a=2
+	QueryArrayRepeat
+)
+
+// QueryOptions configures the query codec, with the defaults of the qs
+// package: brackets for arrays, a depth of 5 and indexes up to 20.
+type QueryOptions struct {
+	ArrayFormat QueryArrayFormat
+	// AllowDots reads and writes a.b=1 instead of a[b]=1
+	AllowDots bool
+	// Depth is the number of nested keys parsed, the rest of a deeper key
+	// is kept as one literal key.
+	Depth int
+	// ArrayLimit is the largest index parsed as an array index, larger
+	// indexes become map keys.
+	ArrayLimit int
+	Delimiter  string
+	// Tag is the struct tag holding the field name, "query" by default.
+	Tag string
+}
+
+func WithQueryArrayFormat(format QueryArrayFormat) DataOption[QueryOptions] {
+	return func(o *QueryOptions) {
+		o.ArrayFormat = format
+	}
+}
+
+func WithQueryDots() DataOption[QueryOptions] {
+	return func(o *QueryOptions) {
+		o.AllowDots = true
+	}
+}
+
+func WithQueryDepth(depth int) DataOption[QueryOptions] {
+	return func(o *QueryOptions) {
+		o.Depth = depth
+	}
+}
+
+func WithQueryArrayLimit(limit int) DataOption[QueryOptions] {
+	return func(o *QueryOptions) {
+		o.ArrayLimit = limit
+	}
+}
+
+func WithQueryDelimiter(delimiter string) DataOption[QueryOptions] {
+	return func(o *QueryOptions) {
+		o.Delimiter = delimiter
+	}
+}
+
+func WithQueryTag(tag string) DataOption[QueryOptions] {
+	return func(o *QueryOptions) {
+		o.Tag = tag
+	}
+}
+
+func newQueryOptions(opts []DataOption[QueryOptions]) QueryOptions {
+	options := QueryOptions{Depth: 5, ArrayLimit: 20, Delimiter: "&", Tag: "query"}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// queryList collects array items by index while parsing, sparse indexes
+// are compacted at the end as qs does.
+type queryList struct {
+	items map[int]any
+	next  int
+}
+
+func (l *queryList) set(index int, value any) {
+	l.items[index] = value
+	if index >= l.next {
+		l.next = index + 1
+	}
+}
+
+// dotsToBrackets rewrites "a.b[c].d" as "a[b][c][d]".
+func dotsToBrackets(key string) string {
+	var builder strings.Builder
+	inBracket, dotted := false, false
+	for _, char := range key {
+		switch {
+		case inBracket:
+			inBracket = char != ']'
+		case char == '.' || char == '[':
+			if dotted {
+				builder.WriteByte(']')
+			}
+			dotted, inBracket = char == '.', char == '['
+			if dotted {
+				builder.WriteByte('[')
+				continue
+			}
+		}
+		builder.WriteRune(char)
+	}
+	if dotted {
+		builder.WriteByte(']')
+	}
+	return builder.String()
+}
+
+// splitQueryKey splits "a[b][]" into ["a", "b", ""]. Keys nested deeper
+// than the depth limit keep the rest as one segment, such as "[e][f]".
+func splitQueryKey(key string, options QueryOptions) []string {
+	if options.AllowDots {
+		key = dotsToBrackets(key)
+	}
+	open := strings.Index(key, "[")
+	if open < 0 || !strings.Contains(key[open:], "]") {
+		return []string{key}
+	}
+	segments := []string{}
+	if open > 0 {
+		segments = append(segments, key[:open])
+	}
+	rest := key[open:]
+	for len(rest) > 0 && rest[0] == '[' && len(segments) <= options.Depth {
+		closing := strings.Index(rest, "]")
+		if closing < 0 {
+			break
+		}
+		segments = append(segments, rest[1:closing])
+		rest = rest[closing+1:]
+	}
+	if rest != "" {
+		segments = append(segments, rest)
+	}
+	return segments
+}
+
+func (o QueryOptions) arrayIndex(segment string) (int, bool) {
+	if segment == "" || (len(segment) > 1 && segment[0] == '0') {
+		return 0, false
+	}
+	index, err := strconv.Atoi(segment)
+	return index, err == nil && index >= 0 && index <= o.ArrayLimit
+}
+
+// insertQuery puts value at the path of segments below container.
+func insertQuery(container any, segments []string, value any, options QueryOptions) any {
+	if len(segments) == 0 {
+		switch existing := container.(type) {
+		case nil:
+			return value
+		case *queryList:
+			existing.set(existing.next, value)
+			return existing
+		case map[string]any:
+			return existing
+		default:
+			list := &queryList{items: map[int]any{}}
+			list.set(0, existing)
+			list.set(1, value)
+			return list
+		}
+	}
+
+	segment, rest := segments[0], segments[1:]
+	if index, ok := options.arrayIndex(segment); ok || segment == "" {
+		list, isList := container.(*queryList)
+		if container == nil {
+			list, isList = &queryList{items: map[int]any{}}, true
+		}
+		if isList {
+			if segment == "" {
+				index = list.next
+			}
+			list.set(index, insertQuery(list.items[index], rest, value, options))
+			return list
+		}
+	}
+
+	object, ok := container.(map[string]any)
+	if !ok {
+		object = map[string]any{}
+		switch existing := container.(type) {
+		case *queryList:
+			for index, item := range existing.items {
+				object[strconv.Itoa(index)] = item
+			}
+		case nil:
+		default:
+			object[""] = existing
+		}
+	}
+	object[segment] = insertQuery(object[segment], rest, value, options)
+	return object
+}
+
+// compactQuery turns the parsed queryLists into []any.
+func compactQuery(value any) any {
+	switch v := value.(type) {
+	case *queryList:
+		indexes := make([]int, 0, len(v.items))
+		for index := range v.items {
+			indexes = append(indexes, index)
+		}
+		sort.Ints(indexes)
+		list := make([]any, 0, len(indexes))
+		for _, index := range indexes {
+			list = append(list, compactQuery(v.items[index]))
+		}
+		return list
+	case map[string]any:
+		for key, item := range v {
+			v[key] = compactQuery(item)
+		}
+	}
+	return value
+}
+
+// ParseQuery decodes a query string into nested maps, so that
+// "a[b][c]=1&list[]=1&list[]=2" becomes
+// {"a": {"b": {"c": "1"}}, "list": ["1", "2"]}. Repeated keys become arrays.
+func ParseQuery(query string, opts ...DataOption[QueryOptions]) (map[string]any, error) {
+	options := newQueryOptions(opts)
+	result := map[string]any{}
+	for _, part := range strings.Split(strings.TrimPrefix(query, "?"), options.Delimiter) {
+		if part == "" {
+			continue
+		}
+		rawKey, rawValue, _ := strings.Cut(part, "=")
+		key, err := url.QueryUnescape(rawKey)
+		if err != nil {
+			return nil, fmt.Errorf("%w: key %q: %v", ErrInvalidQuery, rawKey, err)
+		}
+		text, err := url.QueryUnescape(rawValue)
+		if err != nil {
+			return nil, fmt.Errorf("%w: value of %q: %v", ErrInvalidQuery, key, err)
+		}
+		if key == "" {
+			continue
+		}
+		var value any = text
+		if options.ArrayFormat == QueryArrayComma && strings.Contains(rawValue, ",") {
+			items := []any{}
+			for _, item := range strings.Split(rawValue, ",") {
+				item, _ = url.QueryUnescape(item)
+				items = append(items, item)
+			}
+			value = items
+		}
+
+		segments := splitQueryKey(key, options)
+		if items, ok := value.([]any); ok {
+			for _, item := range items {
+				result[segments[0]] = insertQuery(result[segments[0]], segments[1:], item, options)
+			}
+			continue
+		}
+		result[segments[0]] = insertQuery(result[segments[0]], segments[1:], value, options)
+	}
+	compactQuery(result)
+	return result, nil
+}
+
+// DecodeQuery parses a query string into target, a pointer to a struct or a
+// map. Struct fields are matched by their query tag or by their name,
+// ignoring case.
+func DecodeQuery(query string, target any, opts ...DataOption[QueryOptions]) error {
+	options := newQueryOptions(opts)
+	values, err := ParseQuery(query, opts...)
+	if err != nil {
+		return err
+	}
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Pointer || value.IsNil() {
+		return fmt.Errorf("%w: DecodeQuery needs a non nil pointer, got %T", ErrInvalidQuery, target)
+	}
+	return assignQuery(value.Elem(), values, "", options)
+}
+
+// queryFieldName returns the name of a struct field in a query, empty for
+// fields tagged "-", and whether the field is omitted when empty.
+func queryFieldName(field reflect.StructField, tag string) (string, bool) {
+	name, flags, _ := strings.Cut(field.Tag.Get(tag), ",")
+	if name == "" {
+		name, flags, _ = strings.Cut(field.Tag.Get("json"), ",")
+	}
+	if name == "-" {
+		return "", false
+	}
+	if name == "" {
+		name = field.Name
+	}
+	return name, strings.Contains(","+flags+",", ",omitempty,")
+}
+
+var queryTimeType = reflect.TypeOf(time.Time{})
+
+func assignQuery(target reflect.Value, source any, path string, options QueryOptions) error {
+	if target.Kind() == reflect.Pointer {
+		if target.IsNil() {
+			target.Set(reflect.New(target.Type().Elem()))
+		}
+		return assignQuery(target.Elem(), source, path, options)
+	}
+	if target.Kind() == reflect.Interface && target.NumMethod() == 0 {
+		target.Set(reflect.ValueOf(source))
+		return nil
+	}
+	invalid := func(err error) error {
+		if err == nil {
+			err = fmt.Errorf("can not assign %T to %s", source, target.Type())
+		}
+		return fmt.Errorf("%w: %s: %v", ErrInvalidQuery, path, err)
+	}
+	join := func(key string) string {
+		if path == "" {
+			return key
+		}
+		return path + "." + key
+	}
+
+	if target.Type() == queryTimeType {
+		date, err := time.Parse(time.RFC3339, ToString(source))
+		if err != nil {
+			return invalid(err)
+		}
+		target.Set(reflect.ValueOf(date))
+		return nil
+	}
+
+	switch target.Kind() {
+	case reflect.Struct:
+		object, ok := source.(map[string]any)
+		if !ok {
+			return invalid(nil)
+		}
+		for i := 0; i < target.NumField(); i++ {
+			field := target.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if field.Anonymous && field.Type.Kind() == reflect.Struct && field.Tag.Get(options.Tag) == "" {
+				if err := assignQuery(target.Field(i), object, path, options); err != nil {
+					return err
+				}
+				continue
+			}
+			name, _ := queryFieldName(field, options.Tag)
+			if name == "" {
+				continue
+			}
+			for key, item := range object {
+				if key == name || strings.EqualFold(key, name) {
+					if err := assignQuery(target.Field(i), item, join(key), options); err != nil {
+						return err
+					}
+					break
+				}
+			}
+		}
+	case reflect.Map:
+		object, ok := source.(map[string]any)
+		if !ok || target.Type().Key().Kind() != reflect.String {
+			return invalid(nil)
+		}
+		if target.IsNil() {
+			target.Set(reflect.MakeMap(target.Type()))
+		}
+		for key, item := range object {
+			element := reflect.New(target.Type().Elem()).Elem()
+			if err := assignQuery(element, item, join(key), options); err != nil {
+				return err
+			}
+			target.SetMapIndex(reflect.ValueOf(key).Convert(target.Type().Key()), element)
+		}
+	case reflect.Slice:
+		items, ok := source.([]any)
+		if !ok {
+			if text, isText := source.(string); isText && options.ArrayFormat == QueryArrayComma && text == "" {
+				items = []any{}
+			} else {
+				items = []any{source}
+			}
+		}
+		slice := reflect.MakeSlice(target.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := assignQuery(slice.Index(i), item, join(strconv.Itoa(i)), options); err != nil {
+				return err
+			}
+		}
+		target.Set(slice)
+	default:
+		if items, ok := source.([]any); ok && len(items) > 0 {
+			// a repeated scalar keeps its last value
+			source = items[len(items)-1]
+		}
+		text, ok := source.(string)
+		if !ok {
+			return invalid(nil)
+		}
+		var err error
+		switch target.Kind() {
+		case reflect.String:
+			target.SetString(text)
+		case reflect.Bool:
+			var value bool
+			if text == "on" || text == "" {
+				value = text == "on"
+			} else {
+				value, err = strconv.ParseBool(text)
+			}
+			target.SetBool(value)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			var value int64
+			value, err = strconv.ParseInt(text, 10, target.Type().Bits())
+			target.SetInt(value)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			var value uint64
+			value, err = strconv.ParseUint(text, 10, target.Type().Bits())
+			target.SetUint(value)
+		case reflect.Float32, reflect.Float64:
+			var value float64
+			value, err = strconv.ParseFloat(text, target.Type().Bits())
+			target.SetFloat(value)
+		default:
+			return invalid(nil)
+		}
+		if err != nil {
+			return invalid(err)
+		}
+	}
+	return nil
+}
+
+// queryTree converts maps, structs and slices to map[string]any and []any
+// with string leaves. Nil values are left out.
+func queryTree(value reflect.Value, options QueryOptions) any {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if !value.IsValid() {
+		return nil
+	}
+	if value.Type() == queryTimeType {
+		return value.Interface().(time.Time).Format(time.RFC3339)
+	}
+	switch value.Kind() {
+	case reflect.Map:
+		object := map[string]any{}
+		iter := value.MapRange()
+		for iter.Next() {
+			if item := queryTree(iter.Value(), options); item != nil {
+				object[ToString(iter.Key().Interface())] = item
+			}
+		}
+		return object
+	case reflect.Struct:
+		object := map[string]any{}
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if field.Anonymous && field.Type.Kind() == reflect.Struct && field.Tag.Get(options.Tag) == "" {
+				for key, item := range queryTree(value.Field(i), options).(map[string]any) {
+					object[key] = item
+				}
+				continue
+			}
+			name, omitEmpty := queryFieldName(field, options.Tag)
+			if name == "" || (omitEmpty && value.Field(i).IsZero()) {
+				continue
+			}
+			if item := queryTree(value.Field(i), options); item != nil {
+				object[name] = item
+			}
+		}
+		return object
+	case reflect.Slice, reflect.Array:
+		if value.Kind() == reflect.Slice && value.IsNil() {
+			return nil
+		}
+		list := []any{}
+		for i := 0; i < value.Len(); i++ {
+			if item := queryTree(value.Index(i), options); item != nil {
+				list = append(list, item)
+			}
+		}
+		return list
+	}
+	return fmt.Sprint(value.Interface())
+}
+
+func (o QueryOptions) escapeKey(key string) string {
+	key = url.QueryEscape(key)
+	if o.AllowDots {
+		key = strings.ReplaceAll(key, ".", "%2E")
+	}
+	return key
+}
+
+func (o QueryOptions) child(prefix string, key string) string {
+	if prefix == "" {
+		return o.escapeKey(key)
+	}
+	if o.AllowDots {
+		return prefix + "." + o.escapeKey(key)
+	}
+	return prefix + "[" + o.escapeKey(key) + "]"
+}
+
+func encodeQuery(prefix string, value any, options QueryOptions, pairs []string) []string {
+	switch v := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			pairs = encodeQuery(options.child(prefix, key), v[key], options, pairs)
+		}
+	case []any:
+		if options.ArrayFormat == QueryArrayComma {
+			items := []string{}
+			for _, item := range v {
+				text, ok := item.(string)
+				if !ok {
+					items = nil
+					break
+				}
+				items = append(items, url.QueryEscape(text))
+			}
+			if items != nil {
+				return append(pairs, prefix+"="+strings.Join(items, ","))
+			}
+		}
+		for i, item := range v {
+			key := prefix
+			_, scalar := item.(string)
+			switch {
+			case options.ArrayFormat == QueryArrayIndices || !scalar:
+				key = prefix + "[" + strconv.Itoa(i) + "]"
+			case options.ArrayFormat == QueryArrayBrackets:
+				key = prefix + "[]"
+			}
+			pairs = encodeQuery(key, item, options, pairs)
+		}
+	case string:
+		pairs = append(pairs, prefix+"="+url.QueryEscape(v))
+	}
+	return pairs
+}
+
+// EncodeQuery writes maps and structs as a query string with sorted keys,
+// the inverse of ParseQuery and DecodeQuery.
+func EncodeQuery(data any, opts ...DataOption[QueryOptions]) (string, error) {
+	options := newQueryOptions(opts)
+	tree, ok := queryTree(reflect.ValueOf(data), options).(map[string]any)
+	if !ok {
+		return "", fmt.Errorf("%w: EncodeQuery needs a map or a struct, got %T", ErrInvalidQuery, data)
+	}
+	return strings.Join(encodeQuery("", tree, options, nil), options.Delimiter), nil
+}
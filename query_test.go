@@ -0,0 +1,103 @@
+package utils_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	utils "github.com/jingyuexing/go-utils"
+)
+
+func TestParseQuery(t *testing.T) {
+	cases := []struct {
+		query    string
+		opts     []utils.DataOption[utils.QueryOptions]
+		expected map[string]any
+	}{
+		{"?a=1&a=2&b=x+y%21", nil, map[string]any{"a": []any{"1", "2"}, "b": "x y!"}},
+		{"a[b][c]=1&a[b][d]=2&flag", nil, map[string]any{"a": map[string]any{"b": map[string]any{"c": "1", "d": "2"}}, "flag": ""}},
+		{"list[]=1&list[]=2&idx[1]=b&idx[0]=a", nil, map[string]any{"list": []any{"1", "2"}, "idx": []any{"a", "b"}}},
+		{"sparse[1]=a&sparse[15]=b&big[21]=c", nil, map[string]any{"sparse": []any{"a", "b"}, "big": map[string]any{"21": "c"}}},
+		{"users[0][name]=a&users[1][name]=b", nil, map[string]any{"users": []any{map[string]any{"name": "a"}, map[string]any{"name": "b"}}}},
+		{"a[b][c][d]=1", []utils.DataOption[utils.QueryOptions]{utils.WithQueryDepth(1)}, map[string]any{"a": map[string]any{"b": map[string]any{"[c][d]": "1"}}}},
+		{"a.b.c=1&a.d[e]=2", []utils.DataOption[utils.QueryOptions]{utils.WithQueryDots()}, map[string]any{"a": map[string]any{"b": map[string]any{"c": "1"}, "d": map[string]any{"e": "2"}}}},
+		{"a=1,2&b=3", []utils.DataOption[utils.QueryOptions]{utils.WithQueryArrayFormat(utils.QueryArrayComma)}, map[string]any{"a": []any{"1", "2"}, "b": "3"}},
+		{"a=1;b=2", []utils.DataOption[utils.QueryOptions]{utils.WithQueryDelimiter(";")}, map[string]any{"a": "1", "b": "2"}},
+	}
+	for _, c := range cases {
+		got, err := utils.ParseQuery(c.query, c.opts...)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c.expected) {
+			t.Error(fmt.Sprintf("Expect: %v, but got %v (%s)", c.expected, got, c.query))
+		}
+	}
+	if _, err := utils.ParseQuery("a=%zz"); !errors.Is(err, utils.ErrInvalidQuery) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrInvalidQuery, err))
+	}
+}
+
+func TestEncodeQuery(t *testing.T) {
+	type Filter struct {
+		Status []string `query:"status"`
+		Min    int      `query:"min,omitempty"`
+	}
+	type Search struct {
+		Query  string `query:"q"`
+		Page   int
+		Filter Filter `query:"filter"`
+		Tags   []string
+		Secret string `query:"-"`
+	}
+	search := Search{Query: "go utils", Page: 2, Filter: Filter{Status: []string{"open", "new"}}, Tags: []string{"a"}, Secret: "x"}
+	cases := []struct {
+		format   utils.QueryArrayFormat
+		expected string
+	}{
+		{utils.QueryArrayBrackets, "Page=2&Tags[]=a&filter[status][]=open&filter[status][]=new&q=go+utils"},
+		{utils.QueryArrayIndices, "Page=2&Tags[0]=a&filter[status][0]=open&filter[status][1]=new&q=go+utils"},
+		{utils.QueryArrayComma, "Page=2&Tags=a&filter[status]=open,new&q=go+utils"},
+		{utils.QueryArrayRepeat, "Page=2&Tags=a&filter[status]=open&filter[status]=new&q=go+utils"},
+	}
+	for _, c := range cases {
+		got, err := utils.EncodeQuery(search, utils.WithQueryArrayFormat(c.format))
+		if err != nil || got != c.expected {
+			t.Error(fmt.Sprintf("Expect: %s, but got %s (%v)", c.expected, got, err))
+		}
+
+		var decoded Search
+		if err := utils.DecodeQuery(got, &decoded, utils.WithQueryArrayFormat(c.format)); err != nil {
+			t.Error(err)
+			continue
+		}
+		search.Secret = ""
+		if !reflect.DeepEqual(decoded, search) {
+			t.Error(fmt.Sprintf("Expect: %+v, but got %+v", search, decoded))
+		}
+	}
+
+	got, _ := utils.EncodeQuery(map[string]any{"a": map[string]any{"b.c": 1}, "d": []int{1}}, utils.WithQueryDots())
+	if got != "a.b%2Ec=1&d[]=1" {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s", "a.b%2Ec=1&d[]=1", got))
+	}
+	if _, err := utils.EncodeQuery("text"); !errors.Is(err, utils.ErrInvalidQuery) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrInvalidQuery, err))
+	}
+
+	var target struct {
+		ID     uint
+		Active bool
+		Ratio  float64
+		Extra  map[string]string
+	}
+	if err := utils.DecodeQuery("id=7&active=on&ratio=0.5&extra[k]=v", &target); err != nil || target.ID != 7 ||
+		!target.Active || target.Ratio != 0.5 || target.Extra["k"] != "v" {
+		t.Error(fmt.Sprintf("Expect: every field decoded, but got %+v (%v)", target, err))
+	}
+	if err := utils.DecodeQuery("id=x", &target); !errors.Is(err, utils.ErrInvalidQuery) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrInvalidQuery, err))
+	}
+}
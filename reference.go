@@ -0,0 +1,263 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	ErrReferenceCycle   = errors.New("reference cycle")
+	ErrMissingReference = errors.New("missing reference")
+)
+
+// ReferenceCycleError reports a reference that refers back to itself,
+// Chain starts and ends with the same name.
+type ReferenceCycleError struct {
+	Chain []string
+}
+
+func (e *ReferenceCycleError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrReferenceCycle, strings.Join(e.Chain, " -> "))
+}
+
+func (e *ReferenceCycleError) Unwrap() error {
+	return ErrReferenceCycle
+}
+
+// MissingReferenceError reports a reference without entry, Chain holds the
+// entries being resolved when it was found.
+type MissingReferenceError struct {
+	Name  string
+	Chain []string
+}
+
+func (e *MissingReferenceError) Error() string {
+	if len(e.Chain) == 0 {
+		return fmt.Sprintf("%s: %q", ErrMissingReference, e.Name)
+	}
+	return fmt.Sprintf("%s: %q in %s", ErrMissingReference, e.Name, strings.Join(e.Chain, " -> "))
+}
+
+func (e *MissingReferenceError) Unwrap() error {
+	return ErrMissingReference
+}
+
+// MissingReference selects what a ReferenceResolver does with a reference
+// to an entry that does not exist.
+type MissingReference int
+
+const (
+	// MissingReferenceComment replaces @name with /*name*/.
+	MissingReferenceComment MissingReference = iota
+	// MissingReferenceKeep leaves @name as it is.
+	MissingReferenceKeep
+	// MissingReferenceEmpty removes @name.
+	MissingReferenceEmpty
+	// MissingReferenceFail fails with a *MissingReferenceError.
+	MissingReferenceFail
+)
+
+// ReferenceResolver expands references such as @name in the entries of a
+// string table, recursively. Entries are looked up in each source in turn,
+// so later sources act as fallbacks. A source is a map[string]string, or a
+// nested map[string]any or struct in which @common.ok is a dotted path.
+// Resolved entries are cached; call Reset after changing a source.
+type ReferenceResolver struct {
+	sources []any
+	symbol  rune
+	escape  rune
+	missing MissingReference
+	mutex   sync.RWMutex
+	cache   map[string]string
+}
+
+// WithReferenceSymbol sets the rune that starts a reference, '@' by default.
+func WithReferenceSymbol(symbol rune) DataOption[ReferenceResolver] {
+	return func(r *ReferenceResolver) {
+		r.symbol = symbol
+	}
+}
+
+// WithReferenceEscape sets the rune that makes the following symbol literal,
+// '\\' by default: `\@name` is written as "@name".
+func WithReferenceEscape(escape rune) DataOption[ReferenceResolver] {
+	return func(r *ReferenceResolver) {
+		r.escape = escape
+	}
+}
+
+func WithMissingReference(mode MissingReference) DataOption[ReferenceResolver] {
+	return func(r *ReferenceResolver) {
+		r.missing = mode
+	}
+}
+
+// WithReferenceFallback adds sources that are searched when an entry is
+// not found in the previous ones, e.g. the messages of a fallback locale.
+func WithReferenceFallback(sources ...any) DataOption[ReferenceResolver] {
+	return func(r *ReferenceResolver) {
+		r.sources = append(r.sources, sources...)
+	}
+}
+
+func NewReferenceResolver(target map[string]string, opts ...DataOption[ReferenceResolver]) *ReferenceResolver {
+	return NewReferenceChain([]any{target}, opts...)
+}
+
+// NewReferenceChain creates a resolver over several sources, the first one
+// that has an entry wins: NewReferenceChain([]any{zhCN, zh, en}).
+func NewReferenceChain(sources []any, opts ...DataOption[ReferenceResolver]) *ReferenceResolver {
+	r := &ReferenceResolver{
+		sources: append([]any{}, sources...),
+		symbol:  '@',
+		escape:  '\\',
+		cache:   make(map[string]string),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Reset clears the cache of resolved entries.
+func (r *ReferenceResolver) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.cache = make(map[string]string)
+}
+
+// entry finds name in the sources. Only strings and other scalar values are
+// entries, a nested map or struct is a namespace.
+func (r *ReferenceResolver) entry(name string) (string, bool) {
+	for _, source := range r.sources {
+		value, ok := lookupPath(source, name)
+		if !ok || value == nil {
+			continue
+		}
+		switch v := value.(type) {
+		case string:
+			return v, true
+		case fmt.Stringer:
+			return v.String(), true
+		}
+		switch reflect.ValueOf(value).Kind() {
+		case reflect.Map, reflect.Struct, reflect.Slice, reflect.Array, reflect.Pointer, reflect.Func, reflect.Chan:
+			continue
+		}
+		return fmt.Sprint(value), true
+	}
+	return "", false
+}
+
+// Get returns the entry key with its references expanded.
+func (r *ReferenceResolver) Get(key string) (string, error) {
+	if _, ok := r.entry(key); !ok {
+		return "", &MissingReferenceError{Name: key}
+	}
+	return r.reference(key, nil)
+}
+
+// Resolve expands the references in text.
+func (r *ReferenceResolver) Resolve(text string) (string, error) {
+	return r.expand(text, nil)
+}
+
+func isReferenceChar(char rune) bool {
+	return unicode.IsLetter(char) || unicode.IsDigit(char) || char == '_'
+}
+
+// referenceName returns the name that starts text. Dots join names but a
+// trailing dot ends the sentence: "@a.b." refers to "a.b".
+func referenceName(text string) string {
+	end := 0
+	for end < len(text) {
+		char, size := utf8.DecodeRuneInString(text[end:])
+		if char == '.' {
+			next, _ := utf8.DecodeRuneInString(text[end+size:])
+			if end == 0 || !isReferenceChar(next) {
+				break
+			}
+		} else if !isReferenceChar(char) {
+			break
+		}
+		end += size
+	}
+	return text[:end]
+}
+
+func (r *ReferenceResolver) expand(text string, chain []string) (string, error) {
+	result := strings.Builder{}
+	previous := rune(0)
+	for idx := 0; idx < len(text); {
+		char, size := utf8.DecodeRuneInString(text[idx:])
+		if char == r.escape && strings.HasPrefix(text[idx+size:], string(r.symbol)) {
+			result.WriteRune(r.symbol)
+			idx += size + utf8.RuneLen(r.symbol)
+			previous = r.symbol
+			continue
+		}
+		// "user@example.com" is not a reference
+		if char != r.symbol || isReferenceChar(previous) {
+			result.WriteRune(char)
+			idx += size
+			previous = char
+			continue
+		}
+		name := referenceName(text[idx+size:])
+		if name == "" {
+			result.WriteRune(char)
+			idx += size
+			previous = char
+			continue
+		}
+		idx += size + len(name)
+		previous = 'a'
+
+		value, err := r.reference(name, chain)
+		if err != nil {
+			return "", err
+		}
+		result.WriteString(value)
+	}
+	return result.String(), nil
+}
+
+func (r *ReferenceResolver) reference(name string, chain []string) (string, error) {
+	for i, entry := range chain {
+		if entry == name {
+			cycle := append(append([]string{}, chain[i:]...), name)
+			return "", &ReferenceCycleError{Chain: cycle}
+		}
+	}
+	r.mutex.RLock()
+	cached, ok := r.cache[name]
+	r.mutex.RUnlock()
+	if ok {
+		return cached, nil
+	}
+	value, ok := r.entry(name)
+	if !ok {
+		switch r.missing {
+		case MissingReferenceKeep:
+			return string(r.symbol) + name, nil
+		case MissingReferenceEmpty:
+			return "", nil
+		case MissingReferenceFail:
+			return "", &MissingReferenceError{Name: name, Chain: append([]string{}, chain...)}
+		}
+		return toComment(name, []string{"/*", "*/"}), nil
+	}
+	expanded, err := r.expand(value, append(chain[:len(chain):len(chain)], name))
+	if err != nil {
+		return "", err
+	}
+	r.mutex.Lock()
+	r.cache[name] = expanded
+	r.mutex.Unlock()
+	return expanded, nil
+}
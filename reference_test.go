@@ -0,0 +1,121 @@
+package utils_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	utils "github.com/jingyuexing/go-utils"
+)
+
+func TestReferenceResolver(t *testing.T) {
+	table := map[string]string{
+		"app":      "go-utils",
+		"greeting": "Welcome to @app!",
+		"quoted":   "(@app), \"@app\"; @app.",
+		"mail":     "write to team@app or \\@app",
+		"nested":   "[@greeting]",
+		"missing":  "see @nothing",
+		"a":        "@b",
+		"b":        "x @c",
+		"c":        "@a",
+		"self":     "@self",
+	}
+	resolver := utils.NewReferenceResolver(table)
+	cases := map[string]string{
+		"greeting": "Welcome to go-utils!",
+		"quoted":   "(go-utils), \"go-utils\"; go-utils.",
+		"mail":     "write to team@app or @app",
+		"nested":   "[Welcome to go-utils!]",
+		"missing":  "see /*nothing*/",
+	}
+	for key, expected := range cases {
+		got, err := resolver.Get(key)
+		if err != nil || got != expected {
+			t.Error(fmt.Sprintf("Expect: %s, but got %s (%v)", expected, got, err))
+		}
+	}
+
+	_, err := resolver.Get("a")
+	cycle := &utils.ReferenceCycleError{}
+	if !errors.As(err, &cycle) || strings.Join(cycle.Chain, ">") != "a>b>c>a" || !errors.Is(err, utils.ErrReferenceCycle) {
+		t.Error(fmt.Sprintf("Expect: %s, but got %v", "reference cycle: a -> b -> c -> a", err))
+	}
+	if _, err := resolver.Get("self"); !errors.Is(err, utils.ErrReferenceCycle) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrReferenceCycle, err))
+	}
+
+	modes := map[utils.MissingReference]string{
+		utils.MissingReferenceKeep:  "see @nothing",
+		utils.MissingReferenceEmpty: "see ",
+	}
+	for mode, expected := range modes {
+		got, _ := utils.NewReferenceResolver(table, utils.WithMissingReference(mode)).Get("missing")
+		if got != expected {
+			t.Error(fmt.Sprintf("Expect: %s, but got %s", expected, got))
+		}
+	}
+	_, err = utils.NewReferenceResolver(table, utils.WithMissingReference(utils.MissingReferenceFail)).Get("nested")
+	if err != nil {
+		t.Error(err)
+	}
+	_, err = utils.NewReferenceResolver(table, utils.WithMissingReference(utils.MissingReferenceFail)).Get("missing")
+	missing := &utils.MissingReferenceError{}
+	if !errors.As(err, &missing) || missing.Name != "nothing" || !errors.Is(err, utils.ErrMissingReference) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrMissingReference, err))
+	}
+
+	hash := utils.NewReferenceResolver(map[string]string{"x": "1"}, utils.WithReferenceSymbol('#'), utils.WithReferenceEscape('!'))
+	if got, _ := hash.Resolve("#x !#x #x"); got != "1 #x 1" {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s", "1 #x 1", got))
+	}
+	if utils.ReferenceString(table, '@')("a") != "" {
+		t.Error("Expect: an empty string for a reference cycle")
+	}
+}
+
+func TestReferenceChain(t *testing.T) {
+	type Errors struct {
+		NotFound string
+	}
+	zh := map[string]any{
+		"common": map[string]any{"ok": "确定"},
+		"dialog": map[string]any{"confirm": "[@common.ok] [@common.cancel]"},
+	}
+	en := map[string]any{
+		"common": map[string]any{"ok": "OK", "cancel": "Cancel", "retries": 3},
+		"errors": Errors{NotFound: "@common.cancel: not found."},
+	}
+	resolver := utils.NewReferenceChain([]any{zh, en})
+	cases := map[string]string{
+		"dialog.confirm":  "[确定] [Cancel]",
+		"errors.NotFound": "Cancel: not found.",
+		"common.retries":  "3",
+	}
+	for key, expected := range cases {
+		got, err := resolver.Get(key)
+		if err != nil || got != expected {
+			t.Error(fmt.Sprintf("Expect: %s, but got %s (%v)", expected, got, err))
+		}
+	}
+	if _, err := resolver.Get("common"); !errors.Is(err, utils.ErrMissingReference) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrMissingReference, err))
+	}
+
+	// resolved entries are cached until Reset
+	zh["common"].(map[string]any)["ok"] = "好"
+	if got, _ := resolver.Get("dialog.confirm"); got != "[确定] [Cancel]" {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s", "[确定] [Cancel]", got))
+	}
+	resolver.Reset()
+	if got, _ := resolver.Get("dialog.confirm"); got != "[好] [Cancel]" {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s", "[好] [Cancel]", got))
+	}
+
+	flat := utils.NewReferenceResolver(map[string]string{"title": "@brand Docs"},
+		utils.WithReferenceFallback(map[string]string{"brand": "Go"}))
+	if got, _ := flat.Get("title"); got != "Go Docs" {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s", "Go Docs", got))
+	}
+}
@@ -0,0 +1,237 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrInvalidRoute = errors.New("invalid route pattern")
+	ErrRouteParam   = errors.New("invalid route parameter")
+)
+
+// routeConstraints are the named constraints of {name:constraint}, any
+// other constraint is a regular expression.
+var routeConstraints = map[string]string{
+	"int":   `-?[0-9]+`,
+	"uint":  `[0-9]+`,
+	"alpha": `[A-Za-z]+`,
+	"alnum": `[A-Za-z0-9]+`,
+	"hex":   `[0-9a-fA-F]+`,
+	"uuid":  `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`,
+}
+
+type routeSegmentKind int
+
+const (
+	routeStatic routeSegmentKind = iota
+	routeParam
+	routeWildcard
+)
+
+type routeSegment struct {
+	kind       routeSegmentKind
+	value      string
+	optional   bool
+	constraint *regexp.Regexp
+}
+
+// RoutePattern is a compiled path pattern such as "/users/{id:int}/*rest".
+// Parameters take a whole segment and are written as :id, {id}, or with a
+// constraint as {id:int} or {slug:[a-z-]+}. A trailing "?" makes a
+// parameter optional and *rest, which must come last, catches the rest of
+// the path, at least one segment unless written *rest?.
+type RoutePattern struct {
+	pattern  string
+	segments []routeSegment
+}
+
+func splitRoutePath(path string) []string {
+	parts := []string{}
+	for _, part := range strings.Split(path, "/") {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+func parseRouteSegment(part string) (routeSegment, error) {
+	segment := routeSegment{kind: routeParam}
+	switch {
+	case strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}"):
+		part = part[1 : len(part)-1]
+		name, constraint, hasConstraint := strings.Cut(part, ":")
+		if hasConstraint {
+			if expression, ok := routeConstraints[constraint]; ok {
+				constraint = expression
+			}
+			pattern, err := regexp.Compile("^(?:" + constraint + ")$")
+			if err != nil {
+				return segment, fmt.Errorf("%w: constraint of %q: %v", ErrInvalidRoute, name, err)
+			}
+			segment.constraint = pattern
+		}
+		part = name
+		if strings.HasPrefix(part, "*") {
+			segment.kind, part = routeWildcard, part[1:]
+		}
+	case strings.HasPrefix(part, ":"):
+		// the legacy :id: form of GetPathValue
+		part = strings.TrimSuffix(part[1:], ":")
+	case strings.HasPrefix(part, "*"):
+		segment.kind, part = routeWildcard, part[1:]
+	default:
+		return routeSegment{kind: routeStatic, value: part}, nil
+	}
+	if strings.HasSuffix(part, "?") {
+		segment.optional, part = true, part[:len(part)-1]
+	}
+	if part == "" {
+		return segment, fmt.Errorf("%w: parameter without a name", ErrInvalidRoute)
+	}
+	segment.value = part
+	return segment, nil
+}
+
+// CompileRoute parses a route pattern.
+func CompileRoute(pattern string) (*RoutePattern, error) {
+	route := &RoutePattern{pattern: pattern}
+	names := map[string]bool{}
+	parts := splitRoutePath(pattern)
+	for i, part := range parts {
+		segment, err := parseRouteSegment(part)
+		if err != nil {
+			return nil, fmt.Errorf("%w in %q", err, pattern)
+		}
+		if segment.kind != routeStatic {
+			if names[segment.value] {
+				return nil, fmt.Errorf("%w: duplicate parameter %q in %q", ErrInvalidRoute, segment.value, pattern)
+			}
+			names[segment.value] = true
+		}
+		if segment.kind == routeWildcard && i != len(parts)-1 {
+			return nil, fmt.Errorf("%w: *%s must be the last segment of %q", ErrInvalidRoute, segment.value, pattern)
+		}
+		route.segments = append(route.segments, segment)
+	}
+	return route, nil
+}
+
+// MustCompileRoute is CompileRoute for patterns known to be valid, it
+// panics on error.
+func MustCompileRoute(pattern string) *RoutePattern {
+	route, err := CompileRoute(pattern)
+	if err != nil {
+		panic(err)
+	}
+	return route
+}
+
+func (r *RoutePattern) String() string {
+	return r.pattern
+}
+
+// Params returns the parameter names in order.
+func (r *RoutePattern) Params() []string {
+	names := []string{}
+	for _, segment := range r.segments {
+		if segment.kind != routeStatic {
+			names = append(names, segment.value)
+		}
+	}
+	return names
+}
+
+func (s routeSegment) accepts(value string) bool {
+	return s.constraint == nil || s.constraint.MatchString(value)
+}
+
+// match matches segments against parts, trying with and without each
+// optional parameter.
+func (r *RoutePattern) match(segments []routeSegment, parts []string, params map[string]string) bool {
+	if len(segments) == 0 {
+		return len(parts) == 0
+	}
+	segment := segments[0]
+	if segment.kind == routeWildcard {
+		values := make([]string, 0, len(parts))
+		for _, part := range parts {
+			value, err := url.PathUnescape(part)
+			if err != nil {
+				return false
+			}
+			values = append(values, value)
+		}
+		rest := strings.Join(values, "/")
+		if len(parts) == 0 {
+			return segment.optional
+		}
+		if !segment.accepts(rest) {
+			return false
+		}
+		params[segment.value] = rest
+		return true
+	}
+	if len(parts) > 0 {
+		switch segment.kind {
+		case routeStatic:
+			if parts[0] == segment.value && r.match(segments[1:], parts[1:], params) {
+				return true
+			}
+		case routeParam:
+			value, err := url.PathUnescape(parts[0])
+			if err == nil && segment.accepts(value) && r.match(segments[1:], parts[1:], params) {
+				params[segment.value] = value
+				return true
+			}
+		}
+	}
+	return segment.optional && r.match(segments[1:], parts, params)
+}
+
+// Match matches a request path against the pattern and returns the
+// unescaped parameters. Missing optional parameters are left out.
+func (r *RoutePattern) Match(path string) (map[string]string, bool) {
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+	params := map[string]string{}
+	if !r.match(r.segments, splitRoutePath(path), params) {
+		return nil, false
+	}
+	return params, true
+}
+
+// Build generates a path from params, the reverse of Match. Values are
+// checked against their constraints and escaped.
+func (r *RoutePattern) Build(params map[string]string) (string, error) {
+	parts := []string{}
+	for _, segment := range r.segments {
+		if segment.kind == routeStatic {
+			parts = append(parts, segment.value)
+			continue
+		}
+		value, ok := params[segment.value]
+		if !ok || value == "" {
+			if segment.optional {
+				continue
+			}
+			return "", fmt.Errorf("%w: missing %q for %q", ErrRouteParam, segment.value, r.pattern)
+		}
+		if !segment.accepts(value) {
+			return "", fmt.Errorf("%w: %q does not match the constraint of %q", ErrRouteParam, value, segment.value)
+		}
+		if segment.kind == routeWildcard {
+			for _, part := range splitRoutePath(value) {
+				parts = append(parts, url.PathEscape(part))
+			}
+			continue
+		}
+		parts = append(parts, url.PathEscape(value))
+	}
+	return "/" + strings.Join(parts, "/"), nil
+}
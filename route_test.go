@@ -0,0 +1,85 @@
+package utils_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	utils "github.com/jingyuexing/go-utils"
+)
+
+func TestRoutePattern(t *testing.T) {
+	cases := []struct {
+		pattern, path string
+		expected      map[string]string
+	}{
+		{"/user/:id", "/user/12", map[string]string{"id": "12"}},
+		{"/user/{id}/", "/user/hello%20world", map[string]string{"id": "hello world"}},
+		{"/user/:id", "/user", nil},
+		{"/user/:id", "/user/1/posts", nil},
+		{"/user/:id", "/admin/1", nil},
+		{"/posts/:year?/:month?", "/posts/2024", map[string]string{"year": "2024"}},
+		{"/posts/:year?/:month?", "/posts", map[string]string{}},
+		{"/a/:b?/c", "/a/c", map[string]string{}},
+		{"/a/:b?/c", "/a/x/c?q=1", map[string]string{"b": "x"}},
+		{"/files/*path", "/files/a/b%2Fc/d.txt", map[string]string{"path": "a/b/c/d.txt"}},
+		{"/files/*path", "/files", nil},
+		{"/files/*path?", "/files/", map[string]string{}},
+		{"/items/{id:int}", "/items/-42", map[string]string{"id": "-42"}},
+		{"/items/{id:int}", "/items/abc", nil},
+		{"/blog/{slug:[a-z-]+}", "/blog/hello-go", map[string]string{"slug": "hello-go"}},
+		{"/blog/{slug:[a-z-]+}", "/blog/Hello", nil},
+	}
+	for _, c := range cases {
+		route, err := utils.CompileRoute(c.pattern)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		params, ok := route.Match(c.path)
+		if ok != (c.expected != nil) || (ok && !reflect.DeepEqual(params, c.expected)) {
+			t.Error(fmt.Sprintf("Expect: %v, but got %v %v (%s %s)", c.expected, params, ok, c.pattern, c.path))
+		}
+	}
+
+	for _, pattern := range []string{"/a/*rest/b", "/a/:id/:id", "/a/{id:[}", "/a/:"} {
+		if _, err := utils.CompileRoute(pattern); !errors.Is(err, utils.ErrInvalidRoute) {
+			t.Error(fmt.Sprintf("Expect: %v, but got %v (%s)", utils.ErrInvalidRoute, err, pattern))
+		}
+	}
+	if got := utils.GetPathValue("/user/:id/:tab", "/user/1"); len(got) != 0 {
+		t.Error(fmt.Sprintf("Expect: an empty map for a shorter path, but got %v", got))
+	}
+}
+
+func TestRoutePatternBuild(t *testing.T) {
+	route := utils.MustCompileRoute("/users/{id:int}/:tab?/*rest?")
+	if got := route.Params(); !reflect.DeepEqual(got, []string{"id", "tab", "rest"}) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", []string{"id", "tab", "rest"}, got))
+	}
+	cases := []struct {
+		params   map[string]string
+		expected string
+	}{
+		{map[string]string{"id": "7"}, "/users/7"},
+		{map[string]string{"id": "7", "tab": "a b"}, "/users/7/a%20b"},
+		{map[string]string{"id": "7", "tab": "x", "rest": "a/b"}, "/users/7/x/a/b"},
+	}
+	for _, c := range cases {
+		got, err := route.Build(c.params)
+		if err != nil || got != c.expected {
+			t.Error(fmt.Sprintf("Expect: %s, but got %s (%v)", c.expected, got, err))
+			continue
+		}
+		if params, ok := route.Match(got); !ok || !reflect.DeepEqual(params, c.params) {
+			t.Error(fmt.Sprintf("Expect: %v, but got %v", c.params, params))
+		}
+	}
+	if _, err := route.Build(map[string]string{}); !errors.Is(err, utils.ErrRouteParam) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrRouteParam, err))
+	}
+	if _, err := route.Build(map[string]string{"id": "x"}); !errors.Is(err, utils.ErrRouteParam) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrRouteParam, err))
+	}
+}
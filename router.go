@@ -0,0 +1,319 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+)
+
+type routeParamsKey struct{}
+
+// RouteParams returns the path parameters the Router matched for r.
+func RouteParams(r *http.Request) map[string]string {
+	params, _ := r.Context().Value(routeParamsKey{}).(map[string]string)
+	return params
+}
+
+// RouteParam returns a single path parameter of r.
+func RouteParam(r *http.Request, name string) string {
+	return RouteParams(r)[name]
+}
+
+type routerEdge struct {
+	segment routeSegment
+	node    *routerNode
+}
+
+// routerNode is a node of the radix tree. Static text is compressed along
+// the edges, prefix holds the text between the parent and this node, so
+// "/users" and "/usage" share the node "/us". Parameters and wildcards
+// hang off the node whose text ends with the slash before them.
+type routerNode struct {
+	prefix   string
+	children []*routerNode
+	params   []*routerEdge
+	wildcard *routerEdge
+	handlers map[string]http.Handler
+	pattern  string
+}
+
+func newRouterNode(prefix string) *routerNode {
+	return &routerNode{prefix: prefix, handlers: map[string]http.Handler{}}
+}
+
+func constraintString(segment routeSegment) string {
+	if segment.constraint == nil {
+		return ""
+	}
+	return segment.constraint.String()
+}
+
+// staticChild returns the node for the static text below n, splitting an
+// edge that only shares part of its text.
+func (n *routerNode) staticChild(text string) *routerNode {
+	if text == "" {
+		return n
+	}
+	for i, child := range n.children {
+		common := 0
+		for common < len(text) && common < len(child.prefix) && text[common] == child.prefix[common] {
+			common++
+		}
+		if common == 0 {
+			continue
+		}
+		if common < len(child.prefix) {
+			split := newRouterNode(child.prefix[:common])
+			child.prefix = child.prefix[common:]
+			split.children = []*routerNode{child}
+			n.children[i] = split
+			child = split
+		}
+		return child.staticChild(text[common:])
+	}
+	child := newRouterNode(text)
+	n.children = append(n.children, child)
+	return child
+}
+
+// child returns the node below the parameter or wildcard segment.
+func (n *routerNode) child(segment routeSegment) *routerNode {
+	if segment.kind == routeWildcard {
+		if n.wildcard == nil {
+			n.wildcard = &routerEdge{segment: segment, node: newRouterNode("")}
+		} else if n.wildcard.segment.value != segment.value || constraintString(n.wildcard.segment) != constraintString(segment) {
+			panic(fmt.Sprintf("router: *%s conflicts with *%s", segment.value, n.wildcard.segment.value))
+		}
+		return n.wildcard.node
+	}
+	for _, edge := range n.params {
+		if edge.segment.value == segment.value && constraintString(edge.segment) == constraintString(segment) {
+			return edge.node
+		}
+	}
+	edge := &routerEdge{segment: segment, node: newRouterNode("")}
+	n.params = append(n.params, edge)
+	// constrained parameters are tried before the plain ones
+	sort.SliceStable(n.params, func(a, b int) bool {
+		return n.params[a].segment.constraint != nil && n.params[b].segment.constraint == nil
+	})
+	return edge.node
+}
+
+// lookup finds the node for the rest of the path below n. Static text has
+// priority over parameters and parameters over the wildcard, a failed
+// branch falls back to the next one.
+func (n *routerNode) lookup(rest string, params map[string]string) *routerNode {
+	if rest == "" {
+		if len(n.handlers) > 0 {
+			return n
+		}
+		return nil
+	}
+	for _, child := range n.children {
+		if child.prefix[0] != rest[0] {
+			continue
+		}
+		if strings.HasPrefix(rest, child.prefix) {
+			if found := child.lookup(rest[len(child.prefix):], params); found != nil {
+				return found
+			}
+		}
+		break
+	}
+	if len(n.params) > 0 {
+		part, remaining := rest, ""
+		if slash := strings.IndexByte(rest, '/'); slash >= 0 {
+			part, remaining = rest[:slash], rest[slash:]
+		}
+		value, err := url.PathUnescape(part)
+		if err == nil && part != "" {
+			for _, edge := range n.params {
+				if !edge.segment.accepts(value) {
+					continue
+				}
+				if found := edge.node.lookup(remaining, params); found != nil {
+					params[edge.segment.value] = value
+					return found
+				}
+			}
+		}
+	}
+	if n.wildcard != nil && len(n.wildcard.node.handlers) > 0 {
+		values := []string{}
+		for _, part := range strings.Split(rest, "/") {
+			value, err := url.PathUnescape(part)
+			if err != nil {
+				return nil
+			}
+			values = append(values, value)
+		}
+		joined := strings.Join(values, "/")
+		if n.wildcard.segment.accepts(joined) {
+			params[n.wildcard.segment.value] = joined
+			return n.wildcard.node
+		}
+	}
+	return nil
+}
+
+// routerPath joins the non empty segments of path as the tree stores
+// them, "/" becomes "".
+func routerPath(path string) string {
+	parts := splitRoutePath(path)
+	if len(parts) == 0 {
+		return ""
+	}
+	return "/" + strings.Join(parts, "/")
+}
+
+// Router dispatches requests through a radix tree of routes written in the
+// syntax of RoutePattern, so a lookup costs the depth of the path rather
+// than the number of routes.
+type Router struct {
+	RouterGroup
+	root *routerNode
+	// NotFound handles unknown paths, http.NotFound by default.
+	NotFound http.Handler
+	// MethodNotAllowed handles known paths without a handler for the
+	// method. The Allow header is set before it is called.
+	MethodNotAllowed http.Handler
+}
+
+// RouterGroup registers routes below a common prefix.
+type RouterGroup struct {
+	router *Router
+	prefix string
+}
+
+func NewRouter() *Router {
+	router := &Router{root: newRouterNode("")}
+	router.RouterGroup = RouterGroup{router: router}
+	return router
+}
+
+// Group returns a group of routes starting with prefix.
+func (g *RouterGroup) Group(prefix string) *RouterGroup {
+	return &RouterGroup{router: g.router, prefix: path.Join("/", g.prefix, prefix)}
+}
+
+// Handle registers handler for method and pattern, an empty method
+// matches every method. Like http.ServeMux it panics on an invalid pattern
+// or a route registered twice.
+func (g *RouterGroup) Handle(method string, pattern string, handler http.Handler) {
+	full := strings.TrimSuffix(g.prefix, "/") + "/" + strings.TrimPrefix(pattern, "/")
+	route, err := CompileRoute(full)
+	if err != nil {
+		panic(err)
+	}
+	method = strings.ToUpper(method)
+	// a route with optional parameters is added once with and once
+	// without each of them
+	variants := [][]routeSegment{{}}
+	for _, segment := range route.segments {
+		next := [][]routeSegment{}
+		for _, variant := range variants {
+			if segment.optional {
+				next = append(next, variant)
+			}
+			next = append(next, append(append([]routeSegment{}, variant...), segment))
+		}
+		variants = next
+	}
+	for _, variant := range variants {
+		node := g.router.root
+		for _, segment := range variant {
+			if segment.kind == routeStatic {
+				node = node.staticChild("/" + segment.value)
+			} else {
+				node = node.staticChild("/").child(segment)
+			}
+		}
+		if _, ok := node.handlers[method]; ok {
+			panic(fmt.Sprintf("router: %s %s conflicts with %s", method, full, node.pattern))
+		}
+		node.handlers[method] = handler
+		node.pattern = full
+	}
+}
+
+func (g *RouterGroup) HandleFunc(method string, pattern string, handler http.HandlerFunc) {
+	g.Handle(method, pattern, handler)
+}
+
+func (g *RouterGroup) Get(pattern string, handler http.HandlerFunc) {
+	g.Handle(http.MethodGet, pattern, handler)
+}
+
+func (g *RouterGroup) Post(pattern string, handler http.HandlerFunc) {
+	g.Handle(http.MethodPost, pattern, handler)
+}
+
+func (g *RouterGroup) Put(pattern string, handler http.HandlerFunc) {
+	g.Handle(http.MethodPut, pattern, handler)
+}
+
+func (g *RouterGroup) Patch(pattern string, handler http.HandlerFunc) {
+	g.Handle(http.MethodPatch, pattern, handler)
+}
+
+func (g *RouterGroup) Delete(pattern string, handler http.HandlerFunc) {
+	g.Handle(http.MethodDelete, pattern, handler)
+}
+
+// Lookup returns the handler for method and path with its parameters. The
+// handler is nil when the path is known but not the method.
+func (r *Router) Lookup(method string, path string) (http.Handler, map[string]string, bool) {
+	params := map[string]string{}
+	node := r.root.lookup(routerPath(path), params)
+	if node == nil {
+		return nil, nil, false
+	}
+	handler, ok := node.handlers[method]
+	if !ok && method == http.MethodHead {
+		handler, ok = node.handlers[http.MethodGet]
+	}
+	if !ok {
+		handler = node.handlers[""]
+	}
+	return handler, params, true
+}
+
+func (r *Router) allowed(path string) string {
+	node := r.root.lookup(routerPath(path), map[string]string{})
+	methods := []string{}
+	for method := range node.handlers {
+		if method != "" {
+			methods = append(methods, method)
+		}
+	}
+	sort.Strings(methods)
+	return strings.Join(methods, ", ")
+}
+
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	path := req.URL.EscapedPath()
+	handler, params, found := r.Lookup(req.Method, path)
+	switch {
+	case !found:
+		handler = r.NotFound
+		if handler == nil {
+			handler = http.NotFoundHandler()
+		}
+	case handler == nil:
+		w.Header().Set("Allow", r.allowed(path))
+		handler = r.MethodNotAllowed
+		if handler == nil {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			})
+		}
+	default:
+		req = req.WithContext(context.WithValue(req.Context(), routeParamsKey{}, params))
+	}
+	handler.ServeHTTP(w, req)
+}
@@ -0,0 +1,120 @@
+package utils_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	utils "github.com/jingyuexing/go-utils"
+)
+
+func TestRouter(t *testing.T) {
+	router := utils.NewRouter()
+	reply := func(name string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "%s %v", name, utils.RouteParams(r))
+		}
+	}
+	router.Get("/users/new", reply("new"))
+	router.Get("/users/{id:int}", reply("int"))
+	router.Get("/users/:name", reply("name"))
+	router.Post("/users/:name", reply("create"))
+	router.Get("/users/:name/posts/:post?", reply("posts"))
+	router.Get("/static/*path", reply("static"))
+	api := router.Group("/api")
+	v1 := api.Group("v1")
+	v1.Get("/items/:id", reply("item"))
+	v1.HandleFunc("", "/any", reply("any"))
+
+	cases := []struct {
+		method, path string
+		status       int
+		body         string
+	}{
+		{"GET", "/users/new", 200, "new map[]"},
+		{"GET", "/users/42", 200, "int map[id:42]"},
+		{"GET", "/users/bob", 200, "name map[name:bob]"},
+		{"HEAD", "/users/bob", 200, "name map[name:bob]"},
+		{"POST", "/users/bob", 200, "create map[name:bob]"},
+		{"GET", "/users/bob/posts", 200, "posts map[name:bob]"},
+		{"GET", "/users/bob/posts/7/", 200, "posts map[name:bob post:7]"},
+		{"GET", "/static/css/a%2Fb.css", 200, "static map[path:css/a/b.css]"},
+		{"GET", "/api/v1/items/a%20b", 200, "item map[id:a b]"},
+		{"DELETE", "/api/v1/any", 200, "any map[]"},
+		{"GET", "/static", 404, "404 page not found\n"},
+		{"GET", "/missing", 404, "404 page not found\n"},
+		{"DELETE", "/users/bob", 405, "Method Not Allowed\n"},
+	}
+	for _, c := range cases {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(c.method, c.path, nil))
+		if recorder.Code != c.status || recorder.Body.String() != c.body {
+			t.Error(fmt.Sprintf("Expect: %d %q, but got %d %q (%s %s)", c.status, c.body, recorder.Code, recorder.Body.String(), c.method, c.path))
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("PUT", "/users/bob", nil))
+	if allow := recorder.Header().Get("Allow"); allow != "GET, POST" {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s", "GET, POST", allow))
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expect: a panic for a duplicate route")
+		}
+	}()
+	router.Get("/users/:name", reply("again"))
+}
+
+func TestRouterSharedPrefixes(t *testing.T) {
+	router := utils.NewRouter()
+	for _, pattern := range []string{"/users/all", "/user", "/usage/:id", "/users", "/us", "/users/:id", "/", "/u/*rest"} {
+		pattern := pattern
+		router.Get(pattern, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "%s %v", pattern, utils.RouteParams(r))
+		})
+	}
+	cases := map[string]string{
+		"/":           "/ map[]",
+		"/us":         "/us map[]",
+		"/user":       "/user map[]",
+		"/users":      "/users map[]",
+		"/users/":     "/users map[]",
+		"/users/all":  "/users/all map[]",
+		"/users/al":   "/users/:id map[id:al]",
+		"/usage/7":    "/usage/:id map[id:7]",
+		"/u/a/b":      "/u/*rest map[rest:a/b]",
+		"/use":        "404 page not found\n",
+		"/usage":      "404 page not found\n",
+		"/users/a/b":  "404 page not found\n",
+		"/userss/all": "404 page not found\n",
+	}
+	for path, expected := range cases {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("GET", path, nil))
+		if recorder.Body.String() != expected {
+			t.Error(fmt.Sprintf("Expect: %q, but got %q (%s)", expected, recorder.Body.String(), path))
+		}
+	}
+}
+
+func TestRouterServer(t *testing.T) {
+	router := utils.NewRouter()
+	router.Get("/hello/:name", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "hello %s", utils.RouteParam(r, "name"))
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+	response, err := http.Get(server.URL + "/hello/go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	body := make([]byte, 16)
+	n, _ := response.Body.Read(body)
+	if string(body[:n]) != "hello go" {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s", "hello go", body[:n]))
+	}
+}
@@ -0,0 +1,847 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	ErrMissingKey     = errors.New("missing template key")
+	ErrUnknownFilter  = errors.New("unknown template filter")
+	ErrUnknownPartial = errors.New("unknown template")
+	ErrTemplateSyntax = errors.New("template syntax error")
+)
+
+// TemplateFilter transforms a placeholder value, args are the ":" separated
+// arguments written after the filter name.
+type TemplateFilter func(value any, args ...string) (any, error)
+
+var templateFilters = map[string]TemplateFilter{
+	"upper": func(value any, args ...string) (any, error) {
+		return strings.ToUpper(ToString(value)), nil
+	},
+	"lower": func(value any, args ...string) (any, error) {
+		return strings.ToLower(ToString(value)), nil
+	},
+	"trim": func(value any, args ...string) (any, error) {
+		return strings.TrimSpace(ToString(value)), nil
+	},
+	"pad":  padTemplateFilter,
+	"date": dateTemplateFilter,
+}
+
+// padTemplateFilter pads the value to args[0] characters with args[1] (a
+// space by default). A negative width pads at the end.
+func padTemplateFilter(value any, args ...string) (any, error) {
+	if len(args) == 0 {
+		return nil, errors.New("pad: missing width")
+	}
+	width, err := strconv.Atoi(args[0])
+	if err != nil {
+		return nil, fmt.Errorf("pad: invalid width %q", args[0])
+	}
+	padding := " "
+	if len(args) > 1 && args[1] != "" {
+		padding = args[1]
+	}
+	text := ToString(value)
+	missing := max(width, -width) - utf8.RuneCountInString(text)
+	if missing <= 0 {
+		return text, nil
+	}
+	pad := []rune(strings.Repeat(padding, missing))[:missing]
+	if width < 0 {
+		return text + string(pad), nil
+	}
+	return string(pad) + text, nil
+}
+
+// dateTemplateFilter formats time.Time, DateTime, unix seconds or an
+// RFC 3339 / "2006-01-02" string with a DateTime format, "YYYY-MM-DD" by default.
+func dateTemplateFilter(value any, args ...string) (any, error) {
+	format := "YYYY-MM-DD"
+	if len(args) > 0 && args[0] != "" {
+		format = args[0]
+	}
+	var date time.Time
+	switch v := value.(type) {
+	case time.Time:
+		date = v
+	case *time.Time:
+		date = *v
+	case DateTime:
+		return v.Format(format), nil
+	case *DateTime:
+		return v.Format(format), nil
+	case int:
+		date = time.Unix(int64(v), 0)
+	case int64:
+		date = time.Unix(v, 0)
+	case string:
+		var ok bool
+		if date, ok = parseTemplateDate(v); !ok {
+			return nil, fmt.Errorf("date: cannot parse %q", v)
+		}
+	default:
+		return nil, fmt.Errorf("date: unsupported value %T", value)
+	}
+	return From(date).Format(format), nil
+}
+
+// parseTemplateDate reads the RFC 3339 and YYYY-MM-DD dates accepted by the
+// date filter and the {key:spec} date formats.
+func parseTemplateDate(value string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+type templateFilterCall struct {
+	name   string
+	args   []string
+	filter TemplateFilter
+}
+
+type templateNodeKind int
+
+const (
+	templateText templateNodeKind = iota
+	templateVariable
+	templateIf
+	templateEach
+	templatePartial
+	// else and end tags only exist while parsing
+	templateElse
+	templateEnd
+)
+
+type templateNode struct {
+	kind templateNodeKind
+	// text is the source of the node
+	text string
+	// offset is the byte offset of the node in the source, line and column
+	// (in runes) start at 1
+	offset int
+	line   int
+	column int
+	key    string
+	// hasDefault distinguishes {name|""} from a placeholder without default
+	hasDefault   bool
+	defaultValue string
+	// spec is the format written after the key: {price:.2f}
+	spec    string
+	filters []templateFilterCall
+
+	// blocks: {#if !key}, {#each key as item, index} and {>name}
+	negate      bool
+	item        string
+	index       string
+	name        string
+	children    []templateNode
+	alternative []templateNode
+}
+
+// CompiledTemplate is a template parsed once by CompileTemplate and rendered
+// any number of times.
+type CompiledTemplate struct {
+	left       string
+	right      string
+	positional bool
+	strict     bool
+	filters    map[string]TemplateFilter
+	nodes      []templateNode
+	set        *TemplateSet
+}
+
+// WithStrictTemplate makes Render fail with ErrMissingKey when a placeholder
+// has neither a value nor a default. Otherwise the placeholder is kept as is.
+func WithStrictTemplate() DataOption[CompiledTemplate] {
+	return func(t *CompiledTemplate) {
+		t.strict = true
+	}
+}
+
+// WithTemplateFilter registers a filter for this template, it may replace a
+// built-in one.
+func WithTemplateFilter(name string, filter TemplateFilter) DataOption[CompiledTemplate] {
+	return func(t *CompiledTemplate) {
+		t.filters[name] = filter
+	}
+}
+
+// splitTemplateDelimiters understands the placeholder styles of Template:
+// "" and "{}" for {name}, a symmetrical pair such as "[]" or "《》", a single
+// prefix such as ":", "@", "#" or "$", and "?" for positional placeholders.
+// A pair of longer delimiters is separated by a space, e.g. "{{ }}" or "${ }";
+// "{{}}" and "${}" work as well.
+func splitTemplateDelimiters(delimiters string) (string, string) {
+	if delimiters == "" {
+		return "{", "}"
+	}
+	if fields := strings.Fields(delimiters); len(fields) == 2 {
+		return fields[0], fields[1]
+	}
+	runes := []rune(delimiters)
+	// "${}" and "#{}" are a prefixed pair of brackets
+	if len(runes) == 3 {
+		switch string(runes[1:]) {
+		case "{}", "[]", "()", "<>":
+			return string(runes[:2]), string(runes[2:])
+		}
+	}
+	if len(runes)%2 == 1 {
+		return delimiters, ""
+	}
+	return string(runes[:len(runes)/2]), string(runes[len(runes)/2:])
+}
+
+// CompileTemplate parses src with the given delimiters (see Template).
+//
+// Placeholders are written as {name}, {user.name} for nested values,
+// {name|guest} for a default and {name|upper|pad:10} for filters. The
+// built-in filters are upper, lower, trim, pad:width[:char] and
+// date:"YYYY-MM-DD". A segment that is not a filter name is the default;
+// quote it ({name|"upper"}) to use a filter name as default. A backslash
+// before a delimiter writes the delimiter itself.
+//
+// A format spec may follow the key: {price:.2f}, {n:08d}, {amount:,},
+// {ratio:.1%}, {name:>10s} or {date:YYYY-MM-DD}. Numeric specs follow
+// [[fill]align][sign][0][width][,][.precision][type] with the types d, f, e,
+// %, x, X, o, b and s, and are computed with BigNumber; a spec on a date is
+// a DateTime format. Values are looked up by position, {0} and {1}, when
+// data is a slice.
+//
+// Blocks use the same delimiters:
+//
+//	{#if user.admin}...{:else if !user.guest}...{:else}...{/if}
+//	{#each items as item, i}{i}: {item.name}{:else}no items{/each}
+//	{>footer}
+//
+// Without "as", each element is the scope of the block and is also called
+// "this", and the position is "index" (slices) or "key" (maps, in key
+// order). Partials are looked up in the TemplateSet the template belongs to.
+// With prefix delimiters the arguments of a block go in parentheses, e.g.
+// @#if(user.admin) ... @:else ... @/if. Prefix placeholders only take a
+// name, and "?" placeholders are filled by position.
+func CompileTemplate(src string, delimiters string, opts ...DataOption[CompiledTemplate]) (*CompiledTemplate, error) {
+	left, right := splitTemplateDelimiters(delimiters)
+	t := &CompiledTemplate{
+		left:       left,
+		right:      right,
+		positional: delimiters == "?",
+		filters:    make(map[string]TemplateFilter, len(templateFilters)),
+	}
+	for name, filter := range templateFilters {
+		t.filters[name] = filter
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	tokens, err := t.tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	nodes, _, stop, err := buildTemplateTree(tokens, 0)
+	if err != nil {
+		return nil, err
+	}
+	if stop != nil {
+		return nil, templateSyntaxError(stop, "unexpected tag")
+	}
+	t.nodes = nodes
+	return t, nil
+}
+
+func templateError(node *templateNode, err error) error {
+	return fmt.Errorf("template: %s at line %d: %w", node.text, node.line, err)
+}
+
+func templateSyntaxError(node *templateNode, message string) error {
+	return templateError(node, fmt.Errorf("%w: %s", ErrTemplateSyntax, message))
+}
+
+func (t *CompiledTemplate) tokenize(src string) ([]templateNode, error) {
+	tokens := []templateNode{}
+	text := strings.Builder{}
+	flush := func() {
+		if text.Len() > 0 {
+			tokens = append(tokens, templateNode{kind: templateText, text: text.String()})
+			text.Reset()
+		}
+	}
+	position := 0
+	for idx := 0; idx < len(src); {
+		if strings.HasPrefix(src[idx:], `\`+t.left) {
+			text.WriteString(t.left)
+			idx += 1 + len(t.left)
+			continue
+		}
+		if !strings.HasPrefix(src[idx:], t.left) {
+			_, size := utf8.DecodeRuneInString(src[idx:])
+			text.WriteString(src[idx : idx+size])
+			idx += size
+			continue
+		}
+
+		start := idx + len(t.left)
+		var node templateNode
+		var end int
+		var err error
+		switch {
+		case t.positional:
+			node, end = templateNode{kind: templateVariable, key: strconv.Itoa(position)}, start
+			position++
+		case t.right == "":
+			node, end, err = t.parsePrefixed(src, start)
+		default:
+			node, end, err = t.parsePaired(src, start)
+		}
+		node.offset = idx
+		node.line = strings.Count(src[:idx], "\n") + 1
+		node.column = utf8.RuneCountInString(src[strings.LastIndexByte(src[:idx], '\n')+1:idx]) + 1
+		if err != nil {
+			node.text = src[idx:end]
+			if errors.Is(err, ErrUnknownFilter) {
+				return nil, templateError(&node, err)
+			}
+			return nil, templateSyntaxError(&node, err.Error())
+		}
+		if node.kind == templateText {
+			// not a placeholder, keep the delimiter as text
+			text.WriteString(t.left)
+			idx = start
+			continue
+		}
+		flush()
+		node.text = src[idx:end]
+		tokens = append(tokens, node)
+		idx = end
+	}
+	flush()
+	return tokens, nil
+}
+
+func isTemplateNameChar(char rune) bool {
+	return unicode.IsLetter(char) || unicode.IsDigit(char) || char == '_' || char == '.' || char == '-'
+}
+
+func isTemplateName(name string) bool {
+	return name != "" && strings.IndexFunc(name, func(char rune) bool { return !isTemplateNameChar(char) }) < 0
+}
+
+func isTemplateTag(char byte) bool {
+	return char == '#' || char == ':' || char == '/' || char == '>'
+}
+
+func (t *CompiledTemplate) parsePrefixed(src string, start int) (templateNode, int, error) {
+	if start+1 < len(src) && isTemplateTag(src[start]) && unicode.IsLetter(rune(src[start+1])) {
+		end := start + 1
+		for end < len(src) && (IsAlphanum(rune(src[end])) || src[end] == '_' ||
+			(src[end] == '-' && end+1 < len(src) && IsAlphanum(rune(src[end+1])))) {
+			end++
+		}
+		content := src[start:end]
+		if end < len(src) && src[end] == '(' {
+			closing := strings.IndexByte(src[end:], ')')
+			if closing < 0 {
+				return templateNode{}, len(src), errors.New("missing \")\"")
+			}
+			content += " " + src[end+1:end+closing]
+			end += closing + 1
+		}
+		node, err := t.parseTag(content)
+		return node, end, err
+	}
+
+	end := start
+	for end < len(src) {
+		char, size := utf8.DecodeRuneInString(src[end:])
+		if !isTemplateNameChar(char) || char == '-' {
+			break
+		}
+		end += size
+	}
+	// a sentence may end right after the name: "Hello @name."
+	for end > start && src[end-1] == '.' {
+		end--
+	}
+	if end == start {
+		return templateNode{}, start, nil
+	}
+	return templateNode{kind: templateVariable, key: src[start:end]}, end, nil
+}
+
+// splitTemplateQuoted splits s on sep outside double quotes.
+func splitTemplateQuoted(s string, sep byte) []string {
+	parts := []string{}
+	quoted := false
+	last := 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '"' && (i == 0 || s[i-1] != '\\'):
+			quoted = !quoted
+		case s[i] == sep && !quoted:
+			parts = append(parts, s[last:i])
+			last = i + 1
+		}
+	}
+	return append(parts, s[last:])
+}
+
+func unquoteTemplateArg(arg string) string {
+	arg = strings.TrimSpace(arg)
+	if len(arg) >= 2 && arg[0] == '"' && arg[len(arg)-1] == '"' {
+		if unquoted, err := strconv.Unquote(arg); err == nil {
+			return unquoted
+		}
+		return arg[1 : len(arg)-1]
+	}
+	return arg
+}
+
+// parsePaired parses a placeholder whose content starts at start. A content
+// that is not a valid placeholder (an unterminated delimiter, "{}" or JSON)
+// yields a text node.
+func (t *CompiledTemplate) parsePaired(src string, start int) (templateNode, int, error) {
+	quoted := false
+	closing := -1
+	for i := start; i < len(src); i++ {
+		if src[i] == '"' && src[i-1] != '\\' {
+			quoted = !quoted
+		} else if !quoted && strings.HasPrefix(src[i:], t.right) {
+			closing = i
+			break
+		}
+	}
+	if closing < 0 {
+		return templateNode{}, start, nil
+	}
+	end := closing + len(t.right)
+	content := strings.TrimSpace(src[start:closing])
+	if content != "" && isTemplateTag(content[0]) && len(content) > 1 {
+		node, err := t.parseTag(content)
+		return node, end, err
+	}
+
+	segments := splitTemplateQuoted(content, '|')
+	key, spec, hasSpec := strings.Cut(segments[0], ":")
+	key = strings.TrimSpace(key)
+	if !isTemplateName(key) {
+		return templateNode{}, start, nil
+	}
+	node := templateNode{kind: templateVariable, key: key}
+	if hasSpec {
+		node.spec = unquoteTemplateArg(spec)
+		if node.spec == "" {
+			return node, end, errors.New("empty format")
+		}
+	}
+	for _, segment := range segments[1:] {
+		segment = strings.TrimSpace(segment)
+		args := splitTemplateQuoted(segment, ':')
+		name := strings.TrimSpace(args[0])
+		if filter, ok := t.filters[name]; ok {
+			call := templateFilterCall{name: name, filter: filter}
+			for _, arg := range args[1:] {
+				call.args = append(call.args, unquoteTemplateArg(arg))
+			}
+			node.filters = append(node.filters, call)
+			continue
+		}
+		if node.hasDefault {
+			return node, end, fmt.Errorf("%w: %q", ErrUnknownFilter, name)
+		}
+		node.hasDefault = true
+		node.defaultValue = unquoteTemplateArg(segment)
+	}
+	return node, end, nil
+}
+
+// parseTag parses the content of a block tag such as "#if !a.b",
+// "#each items as item, i", ":else", ":else if x", "/if" or ">partial".
+func (t *CompiledTemplate) parseTag(content string) (templateNode, error) {
+	tag := content[0]
+	fields := strings.Fields(content[1:])
+	if len(fields) == 0 {
+		return templateNode{}, errors.New("empty tag")
+	}
+	word, args := fields[0], fields[1:]
+	condition := func(node templateNode, args []string) (templateNode, error) {
+		if len(args) != 1 {
+			return node, errors.New("expected one condition")
+		}
+		node.key = args[0]
+		if strings.HasPrefix(node.key, "!") {
+			node.negate = true
+			node.key = node.key[1:]
+		}
+		if !isTemplateName(node.key) {
+			return node, fmt.Errorf("invalid condition %q", args[0])
+		}
+		return node, nil
+	}
+
+	switch {
+	case tag == '#' && word == "if":
+		return condition(templateNode{kind: templateIf}, args)
+	case tag == '#' && word == "each":
+		node := templateNode{kind: templateEach}
+		if len(args) == 0 || !isTemplateName(args[0]) {
+			return node, errors.New("each expects a key")
+		}
+		node.key = args[0]
+		if len(args) > 1 {
+			names := strings.Split(strings.Join(args[2:], ""), ",")
+			if args[1] != "as" || len(names) > 2 || !isTemplateName(names[0]) || (len(names) == 2 && !isTemplateName(names[1])) {
+				return node, errors.New(`expected "each key as item, index"`)
+			}
+			node.item = names[0]
+			if len(names) == 2 {
+				node.index = names[1]
+			}
+		}
+		return node, nil
+	case tag == ':' && word == "else" && len(args) == 0:
+		return templateNode{kind: templateElse}, nil
+	case tag == ':' && word == "else" && args[0] == "if":
+		return condition(templateNode{kind: templateElse}, args[1:])
+	case tag == ':' && word == "elseif":
+		return condition(templateNode{kind: templateElse}, args)
+	case tag == '/' && (word == "if" || word == "each") && len(args) == 0:
+		return templateNode{kind: templateEnd, name: word}, nil
+	case tag == '>' && len(args) == 0:
+		return templateNode{kind: templatePartial, name: word}, nil
+	}
+	return templateNode{}, fmt.Errorf("unknown tag %q", content)
+}
+
+// buildTemplateTree nests the tokens from i on into blocks. It stops at an
+// else or end tag and returns it to the enclosing block.
+func buildTemplateTree(tokens []templateNode, i int) ([]templateNode, int, *templateNode, error) {
+	nodes := []templateNode{}
+	for i < len(tokens) {
+		token := tokens[i]
+		i++
+		switch token.kind {
+		case templateElse, templateEnd:
+			return nodes, i, &token, nil
+		case templateIf, templateEach:
+			var err error
+			if i, err = buildTemplateBlock(tokens, i, &token); err != nil {
+				return nil, i, nil, err
+			}
+		}
+		nodes = append(nodes, token)
+	}
+	return nodes, i, nil, nil
+}
+
+func buildTemplateBlock(tokens []templateNode, i int, block *templateNode) (int, error) {
+	name := "if"
+	if block.kind == templateEach {
+		name = "each"
+	}
+	children, i, stop, err := buildTemplateTree(tokens, i)
+	if err != nil {
+		return i, err
+	}
+	block.children = children
+	if stop != nil && stop.kind == templateElse {
+		if stop.key != "" {
+			if block.kind != templateIf {
+				return i, templateSyntaxError(stop, "else if outside of an if block")
+			}
+			// {:else if x} is an if block nested in the else branch
+			branch := *stop
+			branch.kind = templateIf
+			i, err = buildTemplateBlock(tokens, i, &branch)
+			block.alternative = []templateNode{branch}
+			return i, err
+		}
+		if block.alternative, i, stop, err = buildTemplateTree(tokens, i); err != nil {
+			return i, err
+		}
+		if stop != nil && stop.kind == templateElse {
+			return i, templateSyntaxError(stop, "more than one else")
+		}
+	}
+	if stop == nil {
+		return i, templateSyntaxError(block, "block is not closed")
+	}
+	if stop.name != name {
+		return i, templateSyntaxError(stop, "expected {/"+name+"}")
+	}
+	return i, nil
+}
+
+// templateScope holds the variables of the blocks being rendered.
+type templateScope struct {
+	parent *templateScope
+	data   any
+	vars   map[string]any
+}
+
+// lookupPath finds key in data, first as a plain key and then as a dotted path.
+func lookupPath(data any, key string) (any, bool) {
+	if value, ok := lookupNested(data, []string{key}); ok || !strings.Contains(key, ".") {
+		return value, ok
+	}
+	return lookupNested(data, strings.Split(key, "."))
+}
+
+func (t *CompiledTemplate) resolve(scope *templateScope, key string) (any, bool) {
+	head, rest, nested := strings.Cut(key, ".")
+	for ; scope != nil; scope = scope.parent {
+		if value, ok := scope.vars[head]; ok {
+			if !nested {
+				return value, true
+			}
+			return lookupPath(value, rest)
+		}
+		if scope.data != nil {
+			if value, ok := lookupPath(scope.data, key); ok {
+				return value, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// templateTruthy reports whether value counts as true in an if block: false,
+// zero numbers, empty strings, collections and nil do not.
+func templateTruthy(value any) bool {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() {
+		return false
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() > 0
+	case reflect.Pointer, reflect.Interface:
+		return !v.IsNil()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return !v.IsZero()
+	}
+	return true
+}
+
+// Render fills the template with data, a map, a struct or, for positional
+// templates, a slice. A placeholder whose value is missing, nil or an empty
+// string uses its default.
+func (t *CompiledTemplate) Render(data any) (string, error) {
+	result := strings.Builder{}
+	if err := t.render(t.nodes, &templateScope{data: data}, &result, 0); err != nil {
+		return "", err
+	}
+	return result.String(), nil
+}
+
+// RenderArgs fills a positional template, "?" placeholders or {0}, {1}, ...
+// in the order of args.
+func (t *CompiledTemplate) RenderArgs(args ...any) (string, error) {
+	return t.Render(args)
+}
+
+// maxTemplateDepth bounds recursive partials.
+const maxTemplateDepth = 100
+
+func (t *CompiledTemplate) render(nodes []templateNode, scope *templateScope, result *strings.Builder, depth int) error {
+	for _, node := range nodes {
+		var err error
+		switch node.kind {
+		case templateText:
+			result.WriteString(node.text)
+		case templateVariable:
+			err = t.renderVariable(node, scope, result)
+		case templateIf:
+			value, _ := t.resolve(scope, node.key)
+			if templateTruthy(value) != node.negate {
+				err = t.render(node.children, scope, result, depth)
+			} else {
+				err = t.render(node.alternative, scope, result, depth)
+			}
+		case templateEach:
+			err = t.renderEach(node, scope, result, depth)
+		case templatePartial:
+			err = t.renderPartial(node, scope, result, depth)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *CompiledTemplate) renderVariable(node templateNode, scope *templateScope, result *strings.Builder) error {
+	value, ok := t.resolve(scope, node.key)
+	// an empty string is a value, it only falls back to an explicit default
+	if !ok || value == nil || (value == "" && node.hasDefault) {
+		if node.hasDefault {
+			value = node.defaultValue
+		} else if t.strict {
+			return fmt.Errorf("%w: %q at line %d", ErrMissingKey, node.key, node.line)
+		} else {
+			result.WriteString(node.text)
+			return nil
+		}
+	}
+	if node.spec != "" {
+		formatted, err := formatTemplateValue(value, node.spec)
+		if err != nil {
+			return templateError(&node, err)
+		}
+		value = formatted
+	}
+	for _, call := range node.filters {
+		var err error
+		if value, err = call.filter(value, call.args...); err != nil {
+			return templateError(&node, err)
+		}
+	}
+	result.WriteString(ToString(value))
+	return nil
+}
+
+func (t *CompiledTemplate) renderEach(node templateNode, scope *templateScope, result *strings.Builder, depth int) error {
+	collection, _ := t.resolve(scope, node.key)
+	v := reflect.ValueOf(collection)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	type entry struct {
+		position any
+		value    any
+	}
+	entries := []entry{}
+	indexName := "index"
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			entries = append(entries, entry{i, v.Index(i).Interface()})
+		}
+	case reflect.Map:
+		indexName = "key"
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, key := range keys {
+			entries = append(entries, entry{key.Interface(), v.MapIndex(key).Interface()})
+		}
+	}
+	if len(entries) == 0 {
+		return t.render(node.alternative, scope, result, depth)
+	}
+
+	itemName := "this"
+	if node.item != "" {
+		itemName = node.item
+	}
+	if node.index != "" {
+		indexName = node.index
+	}
+	for _, item := range entries {
+		inner := &templateScope{
+			parent: scope,
+			vars:   map[string]any{itemName: item.value, indexName: item.position},
+		}
+		if node.item == "" {
+			inner.data = item.value
+		}
+		if err := t.render(node.children, inner, result, depth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *CompiledTemplate) renderPartial(node templateNode, scope *templateScope, result *strings.Builder, depth int) error {
+	var partial *CompiledTemplate
+	if t.set != nil {
+		partial, _ = t.set.Lookup(node.name)
+	}
+	if partial == nil {
+		return fmt.Errorf("%w: %q at line %d", ErrUnknownPartial, node.name, node.line)
+	}
+	if depth >= maxTemplateDepth {
+		return fmt.Errorf("template: partial %q nested more than %d times", node.name, maxTemplateDepth)
+	}
+	return partial.render(partial.nodes, scope, result, depth+1)
+}
+
+// TemplateSet is a group of named templates sharing delimiters and options,
+// whose templates can include each other as partials with {>name}.
+type TemplateSet struct {
+	mutex      sync.RWMutex
+	delimiters string
+	options    []DataOption[CompiledTemplate]
+	templates  map[string]*CompiledTemplate
+}
+
+func NewTemplateSet(delimiters string, opts ...DataOption[CompiledTemplate]) *TemplateSet {
+	return &TemplateSet{
+		delimiters: delimiters,
+		options:    opts,
+		templates:  make(map[string]*CompiledTemplate),
+	}
+}
+
+// Add compiles src and registers it as name, replacing a previous template
+// with the same name.
+func (s *TemplateSet) Add(name string, src string) error {
+	template, err := CompileTemplate(src, s.delimiters, s.options...)
+	if err != nil {
+		return fmt.Errorf("template %q: %w", name, err)
+	}
+	template.set = s
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.templates[name] = template
+	return nil
+}
+
+func (s *TemplateSet) Lookup(name string) (*CompiledTemplate, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	template, ok := s.templates[name]
+	return template, ok
+}
+
+// Render renders the template registered as name.
+func (s *TemplateSet) Render(name string, data any) (string, error) {
+	template, ok := s.Lookup(name)
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownPartial, name)
+	}
+	return template.Render(data)
+}
+
+// templateValues orders the values of data by key, so that "?" placeholders
+// are filled deterministically.
+func templateValues(data map[string]any) []any {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	values := make([]any, 0, len(keys))
+	for _, key := range keys {
+		values = append(values, data[key])
+	}
+	return values
+}
@@ -0,0 +1,216 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// templateFormat is a parsed format spec, written after the key as in
+// {price:.2f}, with the grammar [[fill]align][sign][0][width][,][.precision][type].
+type templateFormat struct {
+	fill      rune
+	align     rune
+	sign      rune
+	zero      bool
+	width     int
+	grouping  bool
+	precision int
+	verb      rune
+}
+
+func isTemplateAlign(char rune) bool {
+	return char == '<' || char == '>' || char == '^'
+}
+
+// parseTemplateFormat parses a numeric or string format spec. It returns
+// false for anything else, which is then used as a DateTime format.
+func parseTemplateFormat(spec string) (templateFormat, bool) {
+	format := templateFormat{fill: ' ', precision: -1}
+	runes := []rune(spec)
+	i := 0
+	if len(runes) > 1 && isTemplateAlign(runes[1]) {
+		format.fill, format.align = runes[0], runes[1]
+		i = 2
+	} else if len(runes) > 0 && isTemplateAlign(runes[0]) {
+		format.align = runes[0]
+		i = 1
+	}
+	if i < len(runes) && (runes[i] == '+' || runes[i] == '-' || runes[i] == ' ') {
+		format.sign = runes[i]
+		i++
+	}
+	if i < len(runes) && runes[i] == '0' {
+		format.zero = true
+		i++
+	}
+	start := i
+	for i < len(runes) && IsNumberic(runes[i]) {
+		i++
+	}
+	format.width, _ = strconv.Atoi(string(runes[start:i]))
+	if i < len(runes) && runes[i] == ',' {
+		format.grouping = true
+		i++
+	}
+	if i < len(runes) && runes[i] == '.' {
+		i++
+		start = i
+		for i < len(runes) && IsNumberic(runes[i]) {
+			i++
+		}
+		if i == start {
+			return format, false
+		}
+		format.precision, _ = strconv.Atoi(string(runes[start:i]))
+	}
+	if i < len(runes) && strings.ContainsRune("dfe%sxXob", runes[i]) {
+		format.verb = runes[i]
+		i++
+	}
+	return format, i == len(runes) && len(runes) > 0
+}
+
+// templateDecimal converts numbers, *BigNumber and numeric strings to a BigNumber.
+func templateDecimal(value any) (*BigNumber, bool) {
+	text := ""
+	switch v := value.(type) {
+	case *BigNumber:
+		return v, v != nil
+	case BigNumber:
+		return &v, true
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		text = fmt.Sprintf("%d", v)
+	case float32:
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			return nil, false
+		}
+		text = strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, false
+		}
+		text = strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		text = strings.TrimSpace(v)
+	default:
+		return nil, false
+	}
+	if !isDecimalString(text) {
+		return nil, false
+	}
+	return NewBigNumber(text), true
+}
+
+func isTemplateDate(value any) bool {
+	switch value.(type) {
+	case time.Time, *time.Time, DateTime, *DateTime:
+		return true
+	}
+	return false
+}
+
+// formatTemplateValue applies the spec of {key:spec} to value. Dates, and
+// strings the date filter parses when spec is not a number format, use spec
+// as a DateTime format. Numbers are rounded with BigNumber so that
+// {amount:,.2f} of a *BigNumber keeps every digit.
+func formatTemplateValue(value any, spec string) (string, error) {
+	format, ok := parseTemplateFormat(spec)
+	isDate := isTemplateDate(value)
+	if text, isText := value.(string); isText && !ok {
+		_, isDate = parseTemplateDate(text)
+	}
+	if isDate {
+		formatted, err := dateTemplateFilter(value, spec)
+		return ToString(formatted), err
+	}
+	if !ok {
+		return "", fmt.Errorf("invalid format %q for %T", spec, value)
+	}
+	if format.verb == 's' {
+		return format.pad("", ToString(value), '<'), nil
+	}
+	number, ok := templateDecimal(value)
+	if !ok {
+		if format.verb == 0 && format.precision < 0 && !format.grouping && format.sign == 0 {
+			return format.pad("", ToString(value), '<'), nil
+		}
+		return "", fmt.Errorf("format %q needs a number, got %T", spec, value)
+	}
+
+	digits := ""
+	suffix := ""
+	switch format.verb {
+	case 'd':
+		format.precision = 0
+	case 'f':
+		if format.precision < 0 {
+			format.precision = 6
+		}
+	case '%':
+		if format.precision < 0 {
+			format.precision = 6
+		}
+		number = number.Multiply(NewBigNumber("100"))
+		suffix = "%"
+	case 'e':
+		if format.precision < 0 {
+			format.precision = 6
+		}
+		float, _ := strconv.ParseFloat(number.String(), 64)
+		digits = strconv.FormatFloat(float, 'e', format.precision, 64)
+	case 'x', 'X', 'o', 'b':
+		integer, err := strconv.ParseInt(number.Round(0, RoundHalfUp).String(), 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("format %q: %v is out of range", spec, value)
+		}
+		base := map[rune]int{'x': 16, 'X': 16, 'o': 8, 'b': 2}[format.verb]
+		digits = strconv.FormatInt(integer, base)
+		if format.verb == 'X' {
+			digits = strings.ToUpper(digits)
+		}
+	}
+	if digits == "" {
+		if format.grouping {
+			digits = number.ToFormat(format.precision)
+		} else if format.precision >= 0 {
+			digits = number.ToFixed(format.precision)
+		} else {
+			digits = number.String()
+		}
+	}
+
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	} else if format.sign == '+' || format.sign == ' ' {
+		sign = string(format.sign)
+	}
+	return format.pad(sign, digits+suffix, '>'), nil
+}
+
+// pad fills text up to the width of the spec. A leading zero in the spec
+// pads with zeros between the sign and the digits.
+func (f templateFormat) pad(sign string, text string, align rune) string {
+	missing := f.width - utf8.RuneCountInString(sign+text)
+	if missing <= 0 {
+		return sign + text
+	}
+	if f.zero && f.align == 0 {
+		return sign + strings.Repeat("0", missing) + text
+	}
+	if f.align != 0 {
+		align = f.align
+	}
+	fill := string(f.fill)
+	switch align {
+	case '<':
+		return sign + text + strings.Repeat(fill, missing)
+	case '^':
+		return strings.Repeat(fill, missing/2) + sign + text + strings.Repeat(fill, missing-missing/2)
+	}
+	return strings.Repeat(fill, missing) + sign + text
+}
@@ -0,0 +1,143 @@
+package utils
+
+import (
+	"sort"
+	"strings"
+)
+
+// TemplateFilterUse is a filter applied to a template variable.
+type TemplateFilterUse struct {
+	Name string
+	Args []string
+}
+
+// TemplateVariable describes one placeholder or block argument of a template.
+type TemplateVariable struct {
+	Name string
+	// Offset is the byte offset of the placeholder, Line and Column (in
+	// runes) start at 1.
+	Offset     int
+	Line       int
+	Column     int
+	Default    string
+	HasDefault bool
+	Format     string
+	Filters    []TemplateFilterUse
+	// Block is "if" or "each" when the name is the argument of a block.
+	Block string
+	// Local is set for names that refer to a loop variable or to the element
+	// of an each block; they are not looked up in the data.
+	Local bool
+}
+
+// TemplateVariables lists the variables of a template in source order.
+type TemplateVariables []TemplateVariable
+
+// Variables describes every variable of the template in source order.
+func (t *CompiledTemplate) Variables() TemplateVariables {
+	variables := TemplateVariables{}
+	collectTemplateVariables(t.nodes, map[string]bool{}, false, &variables)
+	return variables
+}
+
+// TemplateInfo parses src like CompileTemplate and describes its variables.
+func TemplateInfo(src string, delimiter string, opts ...DataOption[CompiledTemplate]) (TemplateVariables, error) {
+	template, err := CompileTemplate(src, delimiter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return template.Variables(), nil
+}
+
+func collectTemplateVariables(nodes []templateNode, locals map[string]bool, inElement bool, variables *TemplateVariables) {
+	for _, node := range nodes {
+		if node.kind != templateVariable && node.kind != templateIf && node.kind != templateEach {
+			continue
+		}
+		head, _, _ := strings.Cut(node.key, ".")
+		variable := TemplateVariable{
+			Name:       node.key,
+			Offset:     node.offset,
+			Line:       node.line,
+			Column:     node.column,
+			Default:    node.defaultValue,
+			HasDefault: node.hasDefault,
+			Format:     node.spec,
+			Local:      locals[head] || inElement,
+		}
+		for _, call := range node.filters {
+			variable.Filters = append(variable.Filters, TemplateFilterUse{Name: call.name, Args: call.args})
+		}
+		switch node.kind {
+		case templateIf:
+			variable.Block = "if"
+			*variables = append(*variables, variable)
+			collectTemplateVariables(node.children, locals, inElement, variables)
+			collectTemplateVariables(node.alternative, locals, inElement, variables)
+		case templateEach:
+			variable.Block = "each"
+			*variables = append(*variables, variable)
+			inner := make(map[string]bool, len(locals)+2)
+			for name := range locals {
+				inner[name] = true
+			}
+			if node.item == "" {
+				inner["this"], inner["index"], inner["key"] = true, true, true
+			} else {
+				inner[node.item] = true
+				if node.index == "" {
+					inner["index"], inner["key"] = true, true
+				} else {
+					inner[node.index] = true
+				}
+			}
+			collectTemplateVariables(node.children, inner, inElement || node.item == "", variables)
+			collectTemplateVariables(node.alternative, locals, inElement, variables)
+		default:
+			*variables = append(*variables, variable)
+		}
+	}
+}
+
+// Names returns the distinct names that are looked up in the data.
+func (v TemplateVariables) Names() []string {
+	names := []string{}
+	seen := map[string]bool{}
+	for _, variable := range v {
+		if !variable.Local && !seen[variable.Name] {
+			seen[variable.Name] = true
+			names = append(names, variable.Name)
+		}
+	}
+	return names
+}
+
+// Validate checks data against the template. Missing lists the names that
+// have no value and no default, unused the keys of data that no variable
+// refers to. Both are sorted.
+func (v TemplateVariables) Validate(data map[string]any) (missing []string, unused []string) {
+	used := map[string]bool{}
+	reported := map[string]bool{}
+	for _, variable := range v {
+		if variable.Local {
+			continue
+		}
+		head, _, _ := strings.Cut(variable.Name, ".")
+		used[variable.Name], used[head] = true, true
+		if variable.HasDefault || variable.Block == "if" || reported[variable.Name] {
+			continue
+		}
+		if _, ok := lookupPath(data, variable.Name); !ok {
+			reported[variable.Name] = true
+			missing = append(missing, variable.Name)
+		}
+	}
+	for key := range data {
+		if !used[key] {
+			unused = append(unused, key)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(unused)
+	return missing, unused
+}
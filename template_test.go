@@ -0,0 +1,273 @@
+package utils_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	utils "github.com/jingyuexing/go-utils"
+)
+
+func TestCompileTemplate(t *testing.T) {
+	type User struct {
+		Name    string
+		Address map[string]any
+	}
+	data := map[string]any{
+		"name":  "alice",
+		"empty": "",
+		"user": User{
+			Name:    "Bob",
+			Address: map[string]any{"city": "Paris"},
+		},
+		"tags":    []string{"go", "utils"},
+		"created": time.Date(2024, 3, 9, 10, 0, 0, 0, time.UTC),
+	}
+	cases := []struct {
+		source, delimiters, expected string
+	}{
+		{"{name} and {name}", "", "alice and alice"},
+		{"{missing|guest} {empty|none}", "", "guest none"},
+		{"Hi {empty}!", "", "Hi !"},
+		{"{missing|\"upper\"}", "", "upper"},
+		{"{name|upper} {user.Name|lower}", "", "ALICE bob"},
+		{"{user.Address.city} {tags.1}", "{}", "Paris utils"},
+		{"[{name|pad:8}] [{name|pad:-8:.}]", "", "[   alice] [alice...]"},
+		{"{created|date:\"YYYY/MM/DD\"} {created|date}", "", "2024/03/09 2024-03-09"},
+		{"\\{name} {} {name", "", "{name} {} {name"},
+		{"{{ name | upper }} {name}", "{{ }}", "ALICE {name}"},
+		{"${name}, $", "${ }", "alice, $"},
+		{"Hello @name. Mail @user.Name@example", "@", "Hello alice. Mail Bob@example"},
+		{"《name》 【name】", "《》", "alice 【name】"},
+		{"{unknown} stays", "", "{unknown} stays"},
+	}
+	for _, c := range cases {
+		template, err := utils.CompileTemplate(c.source, c.delimiters)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		got, err := template.Render(data)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if got != c.expected {
+			t.Error(fmt.Sprintf("Expect: %s, but got %s", c.expected, got))
+		}
+	}
+}
+
+func TestCompileTemplateStrict(t *testing.T) {
+	template, err := utils.CompileTemplate("{a} {b|-}", "", utils.WithStrictTemplate())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := template.Render(map[string]any{"a": 1}); err != nil || got != "1 -" {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s (%v)", "1 -", got, err))
+	}
+	if _, err := template.Render(map[string]any{"b": 1}); !errors.Is(err, utils.ErrMissingKey) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrMissingKey, err))
+	}
+
+	if _, err := utils.CompileTemplate("{a|x|y}", ""); !errors.Is(err, utils.ErrUnknownFilter) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrUnknownFilter, err))
+	}
+
+	reverse := func(value any, args ...string) (any, error) {
+		runes := []rune(utils.ToString(value))
+		for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+			runes[i], runes[j] = runes[j], runes[i]
+		}
+		return string(runes), nil
+	}
+	template, _ = utils.CompileTemplate("{word|reverse|upper}", "", utils.WithTemplateFilter("reverse", reverse))
+	if got, _ := template.Render(map[string]string{"word": "abc"}); got != "CBA" {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s", "CBA", got))
+	}
+}
+
+func TestTemplatePositional(t *testing.T) {
+	template, err := utils.CompileTemplate("INSERT INTO t VALUES (?, ?, \\?)", "?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := template.Render([]any{1, "x"})
+	if !strings.HasSuffix(got, "(1, x, ?)") {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s", "(1, x, ?)", got))
+	}
+}
+
+func TestTemplateBlocks(t *testing.T) {
+	type Item struct {
+		Name  string
+		Price float64
+	}
+	data := map[string]any{
+		"user":   map[string]any{"name": "alice", "admin": false, "guest": true},
+		"items":  []Item{{"pen", 1.5}, {"book", 12}},
+		"none":   []string{},
+		"prices": map[string]int{"b": 2, "a": 1},
+	}
+	cases := []struct {
+		source, delimiters, expected string
+	}{
+		{"{#if user.admin}admin{:else if !user.guest}member{:else}guest{/if}", "", "guest"},
+		{"{#if user.name}hi {user.name}{/if}{#if missing}x{/if}", "", "hi alice"},
+		{"{#each items as item, i}{i}.{item.Name} {/each}", "", "0.pen 1.book "},
+		{"{#each items}{Name}@{index}{#if index}!{/if};{/each}", "", "pen@0;book@1!;"},
+		{"{#each prices as price, name}{name}={price} {/each}", "", "a=1 b=2 "},
+		{"{#each none}x{:else}empty{/each}", "", "empty"},
+		{"{{#each items as item}}{{item.Name|upper}}{{/each}}", "{{ }}", "PENBOOK"},
+		{"@#if(user.guest)[@user.name]@:else-@/if", "@", "[alice]"},
+		{"@#each(items as item)@item.Name,@/each", "@", "pen,book,"},
+	}
+	for _, c := range cases {
+		template, err := utils.CompileTemplate(c.source, c.delimiters)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		got, err := template.Render(data)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if got != c.expected {
+			t.Error(fmt.Sprintf("Expect: %s, but got %s", c.expected, got))
+		}
+	}
+
+	for _, source := range []string{"{#if a}", "{/if}", "{#if a}{/each}", "{#each a}{:else}{:else}{/each}", "{#for a}"} {
+		if _, err := utils.CompileTemplate(source, ""); !errors.Is(err, utils.ErrTemplateSyntax) {
+			t.Error(fmt.Sprintf("Expect: %v, but got %v (%s)", utils.ErrTemplateSyntax, err, source))
+		}
+	}
+}
+
+func TestTemplateSet(t *testing.T) {
+	set := utils.NewTemplateSet("")
+	if err := set.Add("item", "<li>{name}</li>"); err != nil {
+		t.Fatal(err)
+	}
+	if err := set.Add("list", "<ul>{#each items}{>item}{/each}</ul>{>footer}"); err != nil {
+		t.Fatal(err)
+	}
+	data := map[string]any{"items": []map[string]string{{"name": "a"}, {"name": "b"}}}
+	if _, err := set.Render("list", data); !errors.Is(err, utils.ErrUnknownPartial) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrUnknownPartial, err))
+	}
+	set.Add("footer", "<p>{items.1.name}</p>")
+	got, err := set.Render("list", data)
+	if err != nil || got != "<ul><li>a</li><li>b</li></ul><p>b</p>" {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s (%v)", "<ul><li>a</li><li>b</li></ul><p>b</p>", got, err))
+	}
+
+	set.Add("loop", "{>loop}")
+	if _, err := set.Render("loop", nil); err == nil {
+		t.Error("Expect: an error for a partial including itself, but got nil")
+	}
+}
+
+func TestTemplateFormatSpec(t *testing.T) {
+	data := map[string]any{
+		"price":  3.14159,
+		"n":      42,
+		"neg":    -7,
+		"amount": utils.NewBigNumber("1234567890123456789.125"),
+		"ratio":  0.125,
+		"name":   "go",
+		"date":   time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC),
+		"text":   "12.5",
+		"day":    "2024-03-09",
+	}
+	cases := []struct {
+		source, expected string
+	}{
+		{"{price:.2f}", "3.14"},
+		{"{n:08d} {neg:05d} {n:+d}", "00000042 -0007 +42"},
+		{"{amount:,}", "1,234,567,890,123,456,789.125"},
+		{"{amount:,.2f}", "1,234,567,890,123,456,789.13"},
+		{"{amount:.0f}", "1234567890123456789"},
+		{"{ratio:.1%}", "12.5%"},
+		{"{n:x} {n:X} {n:b}", "2a 2A 101010"},
+		{"[{name:>5}] [{name:*^6s}] [{n:<5}]", "[   go] [**go**] [42   ]"},
+		{"{date:YYYY-MM-DD} {date:\"DD/MM/YYYY\"}", "2024-03-09 09/03/2024"},
+		{"{day:\"DD/MM/YYYY\"} {day|date:\"DD/MM/YYYY\"} [{day:>12}]", "09/03/2024 09/03/2024 [  2024-03-09]"},
+		{"{text:.2f} {price:.3f|pad:8:0}", "12.50 0003.142"},
+	}
+	for _, c := range cases {
+		template, err := utils.CompileTemplate(c.source, "")
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		got, err := template.Render(data)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if got != c.expected {
+			t.Error(fmt.Sprintf("Expect: %s, but got %s", c.expected, got))
+		}
+	}
+
+	template, _ := utils.CompileTemplate("{name:.2f}", "")
+	if _, err := template.Render(data); err == nil {
+		t.Error("Expect: an error formatting a string as a number, but got nil")
+	}
+
+	template, _ = utils.CompileTemplate("{0} costs {1:.2f}, {0|upper}", "")
+	if got, _ := template.RenderArgs("tea", 2.5); got != "tea costs 2.50, TEA" {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s", "tea costs 2.50, TEA", got))
+	}
+	template, _ = utils.CompileTemplate("SELECT * FROM t WHERE a = ? AND b = ?", "?")
+	if got, _ := template.RenderArgs(1, "x"); got != "SELECT * FROM t WHERE a = 1 AND b = x" {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s", "SELECT * FROM t WHERE a = 1 AND b = x", got))
+	}
+}
+
+func TestTemplateInfo(t *testing.T) {
+	source := "Hi {name|guest|upper},\n{#each items as item, i}{i}: {item.title} {/each}\n{total:.2f} {date|date:\"YYYY\"}"
+	variables, err := utils.TemplateInfo(source, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, variable := range variables {
+		names = append(names, variable.Name)
+	}
+	if strings.Join(names, ",") != "name,items,i,item.title,total,date" {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s", "name,items,i,item.title,total,date", names))
+	}
+	name := variables[0]
+	if name.Offset != 3 || name.Line != 1 || name.Column != 4 || !name.HasDefault || name.Default != "guest" ||
+		len(name.Filters) != 1 || name.Filters[0].Name != "upper" {
+		t.Error(fmt.Sprintf("Expect: name at 3 (1:4) with default guest and upper, but got %+v", name))
+	}
+	if variables[1].Block != "each" || !variables[3].Local || variables[4].Line != 3 || variables[4].Column != 1 || variables[4].Format != ".2f" {
+		t.Error(fmt.Sprintf("Expect: each block, local item.title and total at 3:1, but got %+v", variables))
+	}
+	if date := variables[5]; len(date.Filters) != 1 || date.Filters[0].Args[0] != "YYYY" {
+		t.Error(fmt.Sprintf("Expect: date filter with YYYY, but got %+v", date))
+	}
+	if got := variables.Names(); strings.Join(got, ",") != "name,items,total,date" {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s", "name,items,total,date", got))
+	}
+
+	missing, unused := variables.Validate(map[string]any{"items": []int{}, "total": 1, "extra": true})
+	if strings.Join(missing, ",") != "date" || strings.Join(unused, ",") != "extra" {
+		t.Error(fmt.Sprintf("Expect: missing [date] and unused [extra], but got %v and %v", missing, unused))
+	}
+
+	for _, delimiters := range []string{"{{ }}", "{{}}", "${ }", "${}"} {
+		left, right := delimiters[:2], strings.TrimSpace(delimiters[2:])
+		source := fmt.Sprintf("a %s user.name %s, %s id|0 %s", left, right, left, right)
+		got := utils.FindVariableNames(source, delimiters)
+		if strings.Join(got, ",") != "user.name,id" {
+			t.Error(fmt.Sprintf("Expect: %s, but got %s (%s)", "user.name,id", got, delimiters))
+		}
+	}
+}
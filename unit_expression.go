@@ -0,0 +1,356 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DimensionVector holds the exponents of the SI base quantities (length,
+// mass, time, current, temperature, amount, luminosity) and of information.
+type DimensionVector [8]int
+
+var (
+	BaseLength      = DimensionVector{1, 0, 0, 0, 0, 0, 0, 0}
+	BaseMass        = DimensionVector{0, 1, 0, 0, 0, 0, 0, 0}
+	BaseTime        = DimensionVector{0, 0, 1, 0, 0, 0, 0, 0}
+	BaseCurrent     = DimensionVector{0, 0, 0, 1, 0, 0, 0, 0}
+	BaseTemperature = DimensionVector{0, 0, 0, 0, 1, 0, 0, 0}
+	BaseAmount      = DimensionVector{0, 0, 0, 0, 0, 1, 0, 0}
+	BaseLuminosity  = DimensionVector{0, 0, 0, 0, 0, 0, 1, 0}
+	BaseInformation = DimensionVector{0, 0, 0, 0, 0, 0, 0, 1}
+)
+
+var dimensionVectorSymbols = [8]string{"m", "kg", "s", "A", "K", "mol", "cd", "bit"}
+
+func (v DimensionVector) Mul(other DimensionVector) DimensionVector {
+	for i := range v {
+		v[i] += other[i]
+	}
+	return v
+}
+
+func (v DimensionVector) Div(other DimensionVector) DimensionVector {
+	for i := range v {
+		v[i] -= other[i]
+	}
+	return v
+}
+
+func (v DimensionVector) Pow(exponent int) DimensionVector {
+	for i := range v {
+		v[i] *= exponent
+	}
+	return v
+}
+
+func (v DimensionVector) IsDimensionless() bool {
+	return v == DimensionVector{}
+}
+
+// String renders the vector in coherent SI units, e.g. "kg·m^2·s^-3".
+func (v DimensionVector) String() string {
+	parts := []string{}
+	for _, i := range []int{1, 0, 2, 3, 4, 5, 6, 7} {
+		switch v[i] {
+		case 0:
+		case 1:
+			parts = append(parts, dimensionVectorSymbols[i])
+		default:
+			parts = append(parts, dimensionVectorSymbols[i]+"^"+strconv.Itoa(v[i]))
+		}
+	}
+	if len(parts) == 0 {
+		return "1"
+	}
+	return strings.Join(parts, "·")
+}
+
+// UnitExpression is a parsed unit expression such as "kg·m/s²". Factor
+// converts a value in the expression to the coherent SI unit of Dimension.
+type UnitExpression struct {
+	Expression string
+	Factor     float64
+	Dimension  DimensionVector
+	// unit is set when the expression is a single unit, which may then be
+	// non-linear (°C, dBm, L/100km).
+	unit  *Unit
+	scale float64
+}
+
+// ToSI converts value in the expression to the coherent SI unit.
+func (e UnitExpression) ToSI(value float64) (float64, error) {
+	if e.unit == nil {
+		return value * e.Factor, nil
+	}
+	base, err := e.unit.ToBase(value)
+	return base * e.scale, err
+}
+
+// FromSI converts value in the coherent SI unit to the expression.
+func (e UnitExpression) FromSI(value float64) (float64, error) {
+	if e.unit == nil {
+		return value / e.Factor, nil
+	}
+	return e.unit.FromBase(value / e.scale)
+}
+
+type unitExpressionParser struct {
+	registry *UnitRegistry
+	source   []rune
+	pos      int
+}
+
+var superscriptDigits = map[rune]rune{
+	'⁰': '0', '¹': '1', '²': '2', '³': '3', '⁴': '4',
+	'⁵': '5', '⁶': '6', '⁷': '7', '⁸': '8', '⁹': '9', '⁻': '-',
+}
+
+func isUnitOperator(char rune) bool {
+	switch char {
+	case '*', '/', '·', '⋅', '×', '(', ')', '^':
+		return true
+	}
+	_, ok := superscriptDigits[char]
+	return ok || unicode.IsSpace(char)
+}
+
+// ParseExpression parses a unit expression made of unit names combined with
+// "*", "·", spaces, "/", parentheses and integer powers written as "^2", "²" or a
+// trailing digit ("m2"). Every unit must belong to a dimension registered
+// with RegisterDimensionVector. Non-linear units (°C, dBm) are only allowed
+// on their own.
+func (r *UnitRegistry) ParseExpression(expression string) (UnitExpression, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	if unit, info, err := r.expressionUnit(strings.TrimSpace(expression)); err == nil {
+		return UnitExpression{
+			Expression: expression,
+			Factor:     unit.Factor * info.scale,
+			Dimension:  *info.vector,
+			unit:       unit,
+			scale:      info.scale,
+		}, nil
+	}
+	parser := &unitExpressionParser{registry: r, source: []rune(expression)}
+	result, err := parser.product()
+	if err != nil {
+		return UnitExpression{}, err
+	}
+	parser.skipSpace()
+	if parser.pos < len(parser.source) {
+		return UnitExpression{}, fmt.Errorf("unit expression %q: unexpected %q", expression, string(parser.source[parser.pos]))
+	}
+	result.Expression = expression
+	return result, nil
+}
+
+func (p *unitExpressionParser) skipSpace() {
+	for p.pos < len(p.source) && unicode.IsSpace(p.source[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *unitExpressionParser) product() (UnitExpression, error) {
+	result, err := p.power()
+	if err != nil {
+		return result, err
+	}
+	for {
+		start := p.pos
+		p.skipSpace()
+		if p.pos >= len(p.source) {
+			return result, nil
+		}
+		operator := p.source[p.pos]
+		switch {
+		case operator == '*' || operator == '/' || operator == '·' || operator == '⋅' || operator == '×':
+			p.pos++
+		case p.pos > start && operator != ')':
+			// units separated by spaces are multiplied: "kg m"
+			operator = '*'
+		default:
+			return result, nil
+		}
+		next, err := p.power()
+		if err != nil {
+			return result, err
+		}
+		if operator == '/' {
+			result.Factor /= next.Factor
+			result.Dimension = result.Dimension.Div(next.Dimension)
+		} else {
+			result.Factor *= next.Factor
+			result.Dimension = result.Dimension.Mul(next.Dimension)
+		}
+	}
+}
+
+func (p *unitExpressionParser) power() (UnitExpression, error) {
+	p.skipSpace()
+	var result UnitExpression
+	var err error
+	exponent := 1
+	if p.pos < len(p.source) && p.source[p.pos] == '(' {
+		p.pos++
+		result, err = p.product()
+		if err != nil {
+			return result, err
+		}
+		p.skipSpace()
+		if p.pos >= len(p.source) || p.source[p.pos] != ')' {
+			return result, fmt.Errorf("unit expression %q: missing \")\"", string(p.source))
+		}
+		p.pos++
+	} else {
+		start := p.pos
+		for p.pos < len(p.source) && !isUnitOperator(p.source[p.pos]) {
+			p.pos++
+		}
+		result, exponent, err = p.atom(string(p.source[start:p.pos]))
+		if err != nil {
+			return result, err
+		}
+	}
+
+	if p.pos < len(p.source) && p.source[p.pos] == '^' {
+		p.pos++
+		start := p.pos
+		for p.pos < len(p.source) && (IsNumberic(p.source[p.pos]) || (p.pos == start && (p.source[p.pos] == '-' || p.source[p.pos] == '+'))) {
+			p.pos++
+		}
+		value, err := strconv.Atoi(string(p.source[start:p.pos]))
+		if err != nil {
+			return result, fmt.Errorf("unit expression %q: invalid exponent %q", string(p.source), string(p.source[start:p.pos]))
+		}
+		exponent *= value
+	} else if p.pos < len(p.source) {
+		digits := []rune{}
+		for p.pos < len(p.source) {
+			digit, ok := superscriptDigits[p.source[p.pos]]
+			if !ok {
+				break
+			}
+			digits = append(digits, digit)
+			p.pos++
+		}
+		if len(digits) > 0 {
+			value, err := strconv.Atoi(string(digits))
+			if err != nil {
+				return result, fmt.Errorf("unit expression %q: invalid exponent %q", string(p.source), string(digits))
+			}
+			exponent *= value
+		}
+	}
+	result.Factor = math.Pow(result.Factor, float64(exponent))
+	result.Dimension = result.Dimension.Pow(exponent)
+	return result, nil
+}
+
+// atom resolves a unit name. A leading number multiplies the unit ("100km")
+// and trailing digits that are not part of a unit name are an exponent ("m2").
+func (p *unitExpressionParser) atom(name string) (UnitExpression, int, error) {
+	if name == "" {
+		return UnitExpression{}, 1, fmt.Errorf("unit expression %q: missing unit", string(p.source))
+	}
+	multiplier := 1.0
+	numberEnd := 0
+	for numberEnd < len(name) && (IsNumberic(rune(name[numberEnd])) || name[numberEnd] == '.') {
+		numberEnd++
+	}
+	if numberEnd > 0 {
+		value, err := strconv.ParseFloat(name[:numberEnd], 64)
+		if err != nil {
+			return UnitExpression{}, 1, fmt.Errorf("unit expression %q: invalid number %q", string(p.source), name[:numberEnd])
+		}
+		multiplier = value
+		name = name[numberEnd:]
+		if name == "" {
+			return UnitExpression{Factor: multiplier}, 1, nil
+		}
+	}
+
+	unit, info, err := p.registry.expressionUnit(name)
+	exponent := 1
+	if errors.Is(err, ErrUnknownUnit) {
+		digitStart := len(name)
+		for digitStart > 0 && IsNumberic(rune(name[digitStart-1])) {
+			digitStart--
+		}
+		if digitStart > 0 && digitStart < len(name) {
+			exponent, _ = strconv.Atoi(name[digitStart:])
+			if alternative, alternativeInfo, alternativeErr := p.registry.expressionUnit(name[:digitStart]); alternativeErr == nil {
+				unit, info, err = alternative, alternativeInfo, nil
+			}
+		}
+	}
+	if err != nil {
+		return UnitExpression{}, 1, err
+	}
+	if unit.Kind != ConversionLinear {
+		return UnitExpression{}, 1, fmt.Errorf("unit expression %q: non-linear unit %q cannot be combined", string(p.source), unit.Symbol)
+	}
+	return UnitExpression{
+		Factor:    multiplier * unit.Factor * info.scale,
+		Dimension: *info.vector,
+	}, exponent, nil
+}
+
+// expressionUnit finds the unit called name among dimensions that have a
+// dimension vector, preferring units that are not dimensionless.
+func (r *UnitRegistry) expressionUnit(name string) (*Unit, *dimensionInfo, error) {
+	units, err := r.candidates(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	var found *Unit
+	dimensioned := false
+	for _, unit := range units {
+		info := r.dimensions[unit.Dimension]
+		if info.vector == nil {
+			continue
+		}
+		hasDimension := !info.vector.IsDimensionless()
+		if found == nil || (hasDimension && !dimensioned) {
+			found, dimensioned = unit, hasDimension
+		} else if hasDimension == dimensioned {
+			return nil, nil, fmt.Errorf("%w: %q", ErrAmbiguousUnit, name)
+		}
+	}
+	if found == nil {
+		if len(units) > 0 {
+			return nil, nil, fmt.Errorf("%w: %q has no dimension vector", ErrDimensionMismatch, name)
+		}
+		return nil, nil, fmt.Errorf("%w: %q", ErrUnknownUnit, name)
+	}
+	return found, r.dimensions[found.Dimension], nil
+}
+
+func (r *UnitRegistry) convertExpression(value float64, from, to string) (float64, error) {
+	fromExpression, err := r.ParseExpression(from)
+	if err != nil {
+		return 0, err
+	}
+	toExpression, err := r.ParseExpression(to)
+	if err != nil {
+		return 0, err
+	}
+	if fromExpression.Dimension != toExpression.Dimension {
+		return 0, fmt.Errorf("%w: cannot convert %q [%s] to %q [%s]",
+			ErrDimensionMismatch, from, fromExpression.Dimension, to, toExpression.Dimension)
+	}
+	si, err := fromExpression.ToSI(value)
+	if err != nil {
+		return 0, err
+	}
+	return toExpression.FromSI(si)
+}
+
+// Dimension returns the dimension vector of a unit expression.
+func (r *UnitRegistry) Dimension(expression string) (DimensionVector, error) {
+	parsed, err := r.ParseExpression(expression)
+	return parsed.Dimension, err
+}
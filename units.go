@@ -0,0 +1,527 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+var (
+	ErrUnknownUnit       = errors.New("unknown unit")
+	ErrUnknownDimension  = errors.New("unknown dimension")
+	ErrDimensionMismatch = errors.New("incompatible dimensions")
+	ErrAmbiguousUnit     = errors.New("ambiguous unit")
+	ErrOutOfDomain       = errors.New("value out of the unit domain")
+)
+
+const (
+	DimensionLength  = "length"
+	DimensionMass    = "mass"
+	DimensionVolume  = "volume"
+	DimensionTime    = "time"
+	DimensionStorage = "storage"
+	DimensionBitRate = "bitrate"
+	DimensionCount   = "count"
+
+	DimensionTemperature = "temperature"
+	DimensionFuelEconomy = "fuel economy"
+	DimensionPower       = "power"
+	DimensionRatio       = "ratio"
+
+	DimensionEnergy    = "energy"
+	DimensionForce     = "force"
+	DimensionPressure  = "pressure"
+	DimensionFrequency = "frequency"
+	DimensionSpeed     = "speed"
+	DimensionArea      = "area"
+	DimensionCurrent   = "current"
+)
+
+// ConversionKind selects how a unit value maps onto the base unit of its dimension.
+type ConversionKind int
+
+const (
+	// ConversionLinear: base = value * Factor.
+	ConversionLinear ConversionKind = iota
+	// ConversionAffine: base = value * Factor + Offset, e.g. °C to K.
+	ConversionAffine
+	// ConversionInverse: base = Factor / value, e.g. L/100km to km/L.
+	ConversionInverse
+	// ConversionLog: base = Factor * 10^(value / Scale), e.g. dBm to W with
+	// Factor 0.001 and Scale 10. Use Scale 20 for amplitude (field) quantities.
+	ConversionLog
+)
+
+// Prefix scales a unit, e.g. "k" (kilo) multiplies by 1000.
+type Prefix struct {
+	Symbol string
+	Name   string
+	Factor float64
+}
+
+// SIPrefixes are the metric prefixes from quetta (1e30) down to quecto (1e-30).
+// Micro is registered both as "µ" and as the ASCII "u".
+var SIPrefixes = []Prefix{
+	{"Q", "quetta", 1e30}, {"R", "ronna", 1e27}, {"Y", "yotta", 1e24}, {"Z", "zetta", 1e21},
+	{"E", "exa", 1e18}, {"P", "peta", 1e15}, {"T", "tera", 1e12}, {"G", "giga", 1e9},
+	{"M", "mega", 1e6}, {"k", "kilo", 1e3}, {"h", "hecto", 1e2}, {"da", "deca", 1e1},
+	{"d", "deci", 1e-1}, {"c", "centi", 1e-2}, {"m", "milli", 1e-3}, {"µ", "micro", 1e-6},
+	{"u", "micro", 1e-6}, {"n", "nano", 1e-9}, {"p", "pico", 1e-12}, {"f", "femto", 1e-15},
+	{"a", "atto", 1e-18}, {"z", "zepto", 1e-21}, {"y", "yocto", 1e-24}, {"r", "ronto", 1e-27},
+	{"q", "quecto", 1e-30},
+}
+
+// DecimalPrefixes are the SI prefixes from kilo to exa, used for storage
+// sizes and data rates (1 kB = 1000 B).
+var DecimalPrefixes = []Prefix{
+	{"k", "kilo", 1e3}, {"M", "mega", 1e6}, {"G", "giga", 1e9},
+	{"T", "tera", 1e12}, {"P", "peta", 1e15}, {"E", "exa", 1e18},
+}
+
+// BinaryPrefixes are the IEC prefixes from kibi to exbi (1 KiB = 1024 B).
+var BinaryPrefixes = []Prefix{
+	{"Ki", "kibi", 1 << 10}, {"Mi", "mebi", 1 << 20}, {"Gi", "gibi", 1 << 30},
+	{"Ti", "tebi", 1 << 40}, {"Pi", "pebi", 1 << 50}, {"Ei", "exbi", 1 << 60},
+}
+
+// StoragePrefixes holds both the decimal and the binary prefixes.
+var StoragePrefixes = append(append([]Prefix{}, DecimalPrefixes...), BinaryPrefixes...)
+
+// Unit describes a unit of a dimension. For linear units Factor is the size
+// of the unit expressed in the base unit of its dimension, Kind, Offset and
+// Scale describe non-linear units. When Prefixes is set every prefixed
+// variant of the symbol and of the aliases is registered as well.
+type Unit struct {
+	Symbol    string
+	Dimension string
+	Factor    float64
+	Kind      ConversionKind
+	Offset    float64
+	Scale     float64
+	Aliases   []string
+	Prefixes  []Prefix
+	generated bool
+}
+
+// ToBase converts value in this unit to the base unit of its dimension.
+func (u Unit) ToBase(value float64) (float64, error) {
+	switch u.Kind {
+	case ConversionAffine:
+		return value*u.Factor + u.Offset, nil
+	case ConversionInverse:
+		if value == 0 {
+			return 0, fmt.Errorf("%w: %v %s", ErrOutOfDomain, value, u.Symbol)
+		}
+		return u.Factor / value, nil
+	case ConversionLog:
+		return u.Factor * math.Pow(10, value/u.Scale), nil
+	default:
+		return value * u.Factor, nil
+	}
+}
+
+// FromBase converts value in the base unit of the dimension to this unit.
+func (u Unit) FromBase(value float64) (float64, error) {
+	switch u.Kind {
+	case ConversionAffine:
+		return (value - u.Offset) / u.Factor, nil
+	case ConversionInverse:
+		if value == 0 {
+			return 0, fmt.Errorf("%w: cannot express 0 in %s", ErrOutOfDomain, u.Symbol)
+		}
+		return u.Factor / value, nil
+	case ConversionLog:
+		if value/u.Factor <= 0 {
+			return 0, fmt.Errorf("%w: cannot express %v in %s", ErrOutOfDomain, value, u.Symbol)
+		}
+		return u.Scale * math.Log10(value/u.Factor), nil
+	default:
+		return value / u.Factor, nil
+	}
+}
+
+// UnitRegistry holds dimensions and their units. Unit names are case
+// sensitive; a name that does not match exactly is looked up case
+// insensitively. The same name may be used in several dimensions ("m" is
+// both metre and million), conversions pick the dimension both units share.
+type UnitRegistry struct {
+	mutex      sync.RWMutex
+	dimensions map[string]*dimensionInfo
+	units      map[string][]*Unit
+}
+
+func NewUnitRegistry() *UnitRegistry {
+	return &UnitRegistry{
+		dimensions: make(map[string]*dimensionInfo),
+		units:      make(map[string][]*Unit),
+	}
+}
+
+type dimensionInfo struct {
+	base   string
+	vector *DimensionVector
+	scale  float64
+}
+
+// RegisterDimension adds a dimension whose base unit is `base`. Units of
+// such a dimension can only be converted to units of the same dimension,
+// use RegisterDimensionVector to let them take part in unit expressions.
+func (r *UnitRegistry) RegisterDimension(name string, base string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.dimensions[name] = &dimensionInfo{base: base, scale: 1}
+}
+
+// RegisterDimensionVector adds a dimension whose base unit equals `scale`
+// times the coherent SI unit described by vector. The gram for example is
+// registered with BaseMass and a scale of 0.001.
+func (r *UnitRegistry) RegisterDimensionVector(name string, base string, vector DimensionVector, scale float64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.dimensions[name] = &dimensionInfo{base: base, vector: &vector, scale: scale}
+}
+
+// Dimensions returns the registered dimension names in alphabetical order.
+func (r *UnitRegistry) Dimensions() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	names := make([]string, 0, len(r.dimensions))
+	for name := range r.dimensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Register adds a unit, its aliases and its prefixed variants. A unit
+// registered explicitly replaces a previous unit of the same name and
+// dimension; generated prefixed names never replace an existing name.
+func (r *UnitRegistry) Register(unit Unit) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, ok := r.dimensions[unit.Dimension]; !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownDimension, unit.Dimension)
+	}
+	if unit.Factor == 0 {
+		return fmt.Errorf("unit %q: factor must not be zero", unit.Symbol)
+	}
+	if unit.Kind == ConversionLog && unit.Scale == 0 {
+		return fmt.Errorf("unit %q: logarithmic units need a scale", unit.Symbol)
+	}
+	unit.generated = false
+	r.add(&unit)
+	for _, prefix := range unit.Prefixes {
+		prefixed := &Unit{
+			Symbol:    prefix.Symbol + unit.Symbol,
+			Dimension: unit.Dimension,
+			Factor:    unit.Factor * prefix.Factor,
+			Kind:      unit.Kind,
+			Offset:    unit.Offset,
+			Scale:     unit.Scale,
+			generated: true,
+		}
+		for _, alias := range unit.Aliases {
+			prefixed.Aliases = append(prefixed.Aliases, prefix.Name+alias)
+			// short aliases are symbols too, such as "mL" for "ml"
+			if utf8.RuneCountInString(alias) <= 3 {
+				prefixed.Aliases = append(prefixed.Aliases, prefix.Symbol+alias)
+			}
+		}
+		r.add(prefixed)
+	}
+	return nil
+}
+
+// MustRegister is like Register but panics on error.
+func (r *UnitRegistry) MustRegister(units ...Unit) *UnitRegistry {
+	for _, unit := range units {
+		if err := r.Register(unit); err != nil {
+			panic(err)
+		}
+	}
+	return r
+}
+
+func (r *UnitRegistry) add(unit *Unit) {
+	for _, name := range append([]string{unit.Symbol}, unit.Aliases...) {
+		r.index(name, unit)
+	}
+}
+
+func (r *UnitRegistry) index(name string, unit *Unit) {
+	for i, exists := range r.units[name] {
+		if exists.Dimension != unit.Dimension {
+			continue
+		}
+		if !unit.generated {
+			r.units[name][i] = unit
+		}
+		return
+	}
+	r.units[name] = append(r.units[name], unit)
+}
+
+// candidates returns the units named `name`. Without an exact match the name
+// is compared case insensitively, which fails if it matches two different
+// units of one dimension ("MM" could be megametre or millimetre).
+func (r *UnitRegistry) candidates(name string) ([]*Unit, error) {
+	if units, ok := r.units[name]; ok {
+		return units, nil
+	}
+	byDimension := map[string]*Unit{}
+	found := []*Unit{}
+	for key, units := range r.units {
+		if !strings.EqualFold(key, name) || !sameByteCase(key, name) {
+			continue
+		}
+		for _, unit := range units {
+			exists, ok := byDimension[unit.Dimension]
+			if ok && exists != unit {
+				return nil, fmt.Errorf("%w: %q matches several units, check the letter case", ErrAmbiguousUnit, name)
+			}
+			if !ok {
+				byDimension[unit.Dimension] = unit
+				found = append(found, unit)
+			}
+		}
+	}
+	return found, nil
+}
+
+// sameByteCase reports whether a and b agree on every "b" and "B", a bit
+// and a byte must never match by letter case alone.
+func sameByteCase(a, b string) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if (a[i] == 'b' || a[i] == 'B') && a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Lookup finds a unit by symbol or alias. An empty dimension searches every
+// dimension and fails with ErrAmbiguousUnit if the name is used by more than one.
+func (r *UnitRegistry) Lookup(name string, dimension string) (Unit, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	units, err := r.candidates(name)
+	if err != nil {
+		return Unit{}, err
+	}
+	found := []*Unit{}
+	for _, unit := range units {
+		if dimension == "" || unit.Dimension == dimension {
+			found = append(found, unit)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return Unit{}, fmt.Errorf("%w: %q", ErrUnknownUnit, name)
+	case 1:
+		return *found[0], nil
+	default:
+		return Unit{}, fmt.Errorf("%w: %q", ErrAmbiguousUnit, name)
+	}
+}
+
+// Convert converts value between two units of the same dimension, or
+// between two unit expressions such as "km/h" and "m/s" whose dimension
+// vectors match.
+func (r *UnitRegistry) Convert(value float64, from, to string) (float64, error) {
+	return r.ConvertIn("", value, from, to)
+}
+
+// ConvertIn is like Convert but only considers units of the given dimension.
+// An empty dimension considers every dimension.
+func (r *UnitRegistry) ConvertIn(dimension string, value float64, from, to string) (float64, error) {
+	fromUnit, toUnit, err := r.resolvePair(dimension, from, to)
+	if err != nil {
+		if dimension == "" && (errors.Is(err, ErrUnknownUnit) || errors.Is(err, ErrDimensionMismatch)) {
+			return r.convertExpression(value, from, to)
+		}
+		return 0, err
+	}
+	if fromUnit.Kind == ConversionLinear && toUnit.Kind == ConversionLinear {
+		return value * fromUnit.Factor / toUnit.Factor, nil
+	}
+	base, err := fromUnit.ToBase(value)
+	if err != nil {
+		return 0, err
+	}
+	return toUnit.FromBase(base)
+}
+
+func (r *UnitRegistry) resolvePair(dimension string, from, to string) (*Unit, *Unit, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	if dimension != "" {
+		if _, ok := r.dimensions[dimension]; !ok {
+			return nil, nil, fmt.Errorf("%w: %q", ErrUnknownDimension, dimension)
+		}
+	}
+	fromUnits, err := r.candidates(from)
+	if err != nil {
+		return nil, nil, err
+	}
+	toUnits, err := r.candidates(to)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(fromUnits) == 0 {
+		return nil, nil, fmt.Errorf("%w: %q", ErrUnknownUnit, from)
+	}
+	if len(toUnits) == 0 {
+		return nil, nil, fmt.Errorf("%w: %q", ErrUnknownUnit, to)
+	}
+	var fromUnit, toUnit *Unit
+	for _, a := range fromUnits {
+		for _, b := range toUnits {
+			if a.Dimension != b.Dimension || (dimension != "" && a.Dimension != dimension) {
+				continue
+			}
+			if fromUnit != nil {
+				return nil, nil, fmt.Errorf("%w: %q and %q exist in several dimensions", ErrAmbiguousUnit, from, to)
+			}
+			fromUnit, toUnit = a, b
+		}
+	}
+	if fromUnit == nil {
+		return nil, nil, fmt.Errorf("%w: cannot convert %q to %q", ErrDimensionMismatch, from, to)
+	}
+	return fromUnit, toUnit, nil
+}
+
+// DefaultUnitRegistry is used by ConversionFunctions when no registry is set.
+var DefaultUnitRegistry = newDefaultUnitRegistry()
+
+func newDefaultUnitRegistry() *UnitRegistry {
+	r := NewUnitRegistry()
+	r.RegisterDimensionVector(DimensionLength, "m", BaseLength, 1)
+	r.RegisterDimensionVector(DimensionMass, "g", BaseMass, 1e-3)
+	r.RegisterDimensionVector(DimensionVolume, "l", BaseLength.Pow(3), 1e-3)
+	r.RegisterDimensionVector(DimensionTime, "s", BaseTime, 1)
+	r.RegisterDimensionVector(DimensionStorage, "B", BaseInformation, 8)
+	r.RegisterDimensionVector(DimensionBitRate, "bps", BaseInformation.Div(BaseTime), 1)
+	r.RegisterDimension(DimensionCount, "")
+	r.RegisterDimensionVector(DimensionTemperature, "K", BaseTemperature, 1)
+	r.RegisterDimensionVector(DimensionFuelEconomy, "km/L", BaseLength.Pow(-2), 1e6)
+	r.RegisterDimensionVector(DimensionPower, "W", BaseMass.Mul(BaseLength.Pow(2)).Div(BaseTime.Pow(3)), 1)
+	r.RegisterDimension(DimensionRatio, "ratio")
+	r.RegisterDimensionVector(DimensionEnergy, "J", BaseMass.Mul(BaseLength.Pow(2)).Div(BaseTime.Pow(2)), 1)
+	r.RegisterDimensionVector(DimensionForce, "N", BaseMass.Mul(BaseLength).Div(BaseTime.Pow(2)), 1)
+	r.RegisterDimensionVector(DimensionPressure, "Pa", BaseMass.Div(BaseLength).Div(BaseTime.Pow(2)), 1)
+	r.RegisterDimensionVector(DimensionFrequency, "Hz", BaseTime.Pow(-1), 1)
+	r.RegisterDimensionVector(DimensionSpeed, "m/s", BaseLength.Div(BaseTime), 1)
+	r.RegisterDimensionVector(DimensionArea, "m²", BaseLength.Pow(2), 1)
+	r.RegisterDimensionVector(DimensionCurrent, "A", BaseCurrent, 1)
+
+	r.MustRegister(
+		Unit{Symbol: "m", Dimension: DimensionLength, Factor: 1, Aliases: []string{"meter", "meters", "metre", "metres"}, Prefixes: SIPrefixes},
+		Unit{Symbol: "in", Dimension: DimensionLength, Factor: 0.0254, Aliases: []string{"inch", "inches"}},
+		Unit{Symbol: "ft", Dimension: DimensionLength, Factor: 0.3048, Aliases: []string{"foot", "feet"}},
+		Unit{Symbol: "yd", Dimension: DimensionLength, Factor: 0.9144, Aliases: []string{"yard", "yards"}},
+		Unit{Symbol: "mi", Dimension: DimensionLength, Factor: 1609.344, Aliases: []string{"mile", "miles"}},
+		Unit{Symbol: "nmi", Dimension: DimensionLength, Factor: 1852, Aliases: []string{"nautical mile", "nautical miles"}},
+		// Chinese market units (市制)
+		Unit{Symbol: "里", Dimension: DimensionLength, Factor: 500, Aliases: []string{"市里", "li"}},
+		Unit{Symbol: "丈", Dimension: DimensionLength, Factor: 10.0 / 3, Aliases: []string{"市丈", "zhang"}},
+		Unit{Symbol: "尺", Dimension: DimensionLength, Factor: 1.0 / 3, Aliases: []string{"市尺", "chi"}},
+		Unit{Symbol: "寸", Dimension: DimensionLength, Factor: 1.0 / 30, Aliases: []string{"市寸", "cun"}},
+
+		Unit{Symbol: "g", Dimension: DimensionMass, Factor: 1, Aliases: []string{"gram", "grams", "gramme", "grammes"}, Prefixes: SIPrefixes},
+		Unit{Symbol: "kg", Dimension: DimensionMass, Factor: 1e3, Aliases: []string{"kgs", "kilogram", "kilograms"}},
+		Unit{Symbol: "t", Dimension: DimensionMass, Factor: 1e6, Aliases: []string{"tonne", "tonnes", "ton", "tons"}},
+		Unit{Symbol: "lb", Dimension: DimensionMass, Factor: 453.59237, Aliases: []string{"lbs", "pound", "pounds"}},
+		Unit{Symbol: "oz", Dimension: DimensionMass, Factor: 28.349523125, Aliases: []string{"ounce", "ounces", "ans"}},
+		Unit{Symbol: "斤", Dimension: DimensionMass, Factor: 500, Aliases: []string{"市斤", "jin"}},
+		Unit{Symbol: "两", Dimension: DimensionMass, Factor: 50, Aliases: []string{"兩", "市两", "liang"}},
+		Unit{Symbol: "钱", Dimension: DimensionMass, Factor: 5, Aliases: []string{"錢", "市钱", "qian"}},
+
+		Unit{Symbol: "l", Dimension: DimensionVolume, Factor: 1, Aliases: []string{"L", "liter", "liters", "litre", "litres"}, Prefixes: SIPrefixes},
+		Unit{Symbol: "gal", Dimension: DimensionVolume, Factor: 3.785411784, Aliases: []string{"gallon", "gallons"}},
+		Unit{Symbol: "qt", Dimension: DimensionVolume, Factor: 0.946352946, Aliases: []string{"quart", "quarts"}},
+		Unit{Symbol: "pt", Dimension: DimensionVolume, Factor: 0.473176473, Aliases: []string{"pint", "pints"}},
+		Unit{Symbol: "floz", Dimension: DimensionVolume, Factor: 0.0295735295625, Aliases: []string{"fl oz", "fluid ounce", "fluid ounces"}},
+
+		Unit{Symbol: "s", Dimension: DimensionTime, Factor: 1, Aliases: []string{"sec", "second", "seconds"}, Prefixes: SIPrefixes},
+		Unit{Symbol: "min", Dimension: DimensionTime, Factor: 60, Aliases: []string{"minute", "minutes"}},
+		Unit{Symbol: "h", Dimension: DimensionTime, Factor: 3600, Aliases: []string{"hr", "hour", "hours"}},
+		Unit{Symbol: "d", Dimension: DimensionTime, Factor: 86400, Aliases: []string{"day", "days"}},
+		Unit{Symbol: "wk", Dimension: DimensionTime, Factor: 604800, Aliases: []string{"week", "weeks"}},
+
+		Unit{Symbol: "B", Dimension: DimensionStorage, Factor: 1, Aliases: []string{"byte", "bytes"}, Prefixes: StoragePrefixes},
+		Unit{Symbol: "bit", Dimension: DimensionStorage, Factor: 0.125, Aliases: []string{"b", "bits"}, Prefixes: StoragePrefixes},
+
+		Unit{Symbol: "bps", Dimension: DimensionBitRate, Factor: 1, Aliases: []string{"bit/s"}, Prefixes: StoragePrefixes},
+		Unit{Symbol: "Kbps", Dimension: DimensionBitRate, Factor: 1e3},
+		Unit{Symbol: "B/s", Dimension: DimensionBitRate, Factor: 8, Aliases: []string{"byte/s", "bytes/s"}, Prefixes: StoragePrefixes},
+
+		Unit{Symbol: "", Dimension: DimensionCount, Factor: 1},
+		Unit{Symbol: "k", Dimension: DimensionCount, Factor: 1e3, Aliases: []string{"thousand", "千"}},
+		Unit{Symbol: "w", Dimension: DimensionCount, Factor: 1e4, Aliases: []string{"万", "萬", "wan"}},
+		Unit{Symbol: "m", Dimension: DimensionCount, Factor: 1e6, Aliases: []string{"million"}},
+		Unit{Symbol: "y", Dimension: DimensionCount, Factor: 1e8, Aliases: []string{"亿", "億", "yi"}},
+		Unit{Symbol: "b", Dimension: DimensionCount, Factor: 1e9, Aliases: []string{"billion"}},
+		// 兆 is the East Asian 10^12 (万亿), not the SI mega.
+		Unit{Symbol: "兆", Dimension: DimensionCount, Factor: 1e12, Aliases: []string{"zhao", "trillion"}},
+
+		Unit{Symbol: "K", Dimension: DimensionTemperature, Factor: 1, Aliases: []string{"kelvin"}},
+		Unit{Symbol: "°C", Dimension: DimensionTemperature, Factor: 1, Offset: 273.15, Kind: ConversionAffine, Aliases: []string{"C", "℃", "degC", "celsius"}},
+		Unit{Symbol: "°F", Dimension: DimensionTemperature, Factor: 5.0 / 9, Offset: 459.67 * 5 / 9, Kind: ConversionAffine, Aliases: []string{"F", "℉", "degF", "fahrenheit"}},
+		Unit{Symbol: "°R", Dimension: DimensionTemperature, Factor: 5.0 / 9, Aliases: []string{"R", "°Ra", "degR", "rankine"}},
+
+		Unit{Symbol: "km/L", Dimension: DimensionFuelEconomy, Factor: 1, Aliases: []string{"kmpl"}},
+		Unit{Symbol: "L/100km", Dimension: DimensionFuelEconomy, Factor: 100, Kind: ConversionInverse},
+		Unit{Symbol: "mpg", Dimension: DimensionFuelEconomy, Factor: 1.609344 / 3.785411784, Aliases: []string{"mpg(US)"}},
+		Unit{Symbol: "mpg(imp)", Dimension: DimensionFuelEconomy, Factor: 1.609344 / 4.54609, Aliases: []string{"mpg(UK)"}},
+
+		Unit{Symbol: "W", Dimension: DimensionPower, Factor: 1, Aliases: []string{"watt", "watts"}, Prefixes: SIPrefixes},
+		Unit{Symbol: "hp", Dimension: DimensionPower, Factor: 745.69987158227022, Aliases: []string{"horsepower"}},
+		Unit{Symbol: "dBW", Dimension: DimensionPower, Factor: 1, Scale: 10, Kind: ConversionLog},
+		Unit{Symbol: "dBm", Dimension: DimensionPower, Factor: 1e-3, Scale: 10, Kind: ConversionLog},
+
+		Unit{Symbol: "ratio", Dimension: DimensionRatio, Factor: 1, Aliases: []string{"x", "times"}},
+		Unit{Symbol: "%", Dimension: DimensionRatio, Factor: 0.01, Aliases: []string{"percent"}},
+		Unit{Symbol: "‰", Dimension: DimensionRatio, Factor: 0.001, Aliases: []string{"permille"}},
+		Unit{Symbol: "dB", Dimension: DimensionRatio, Factor: 1, Scale: 10, Kind: ConversionLog, Aliases: []string{"decibel", "decibels"}},
+
+		Unit{Symbol: "J", Dimension: DimensionEnergy, Factor: 1, Aliases: []string{"joule", "joules"}, Prefixes: SIPrefixes},
+		Unit{Symbol: "Wh", Dimension: DimensionEnergy, Factor: 3600, Aliases: []string{"watt-hour", "watt-hours"}, Prefixes: SIPrefixes},
+		Unit{Symbol: "cal", Dimension: DimensionEnergy, Factor: 4.184, Aliases: []string{"calorie", "calories"}, Prefixes: SIPrefixes},
+		Unit{Symbol: "eV", Dimension: DimensionEnergy, Factor: 1.602176634e-19, Aliases: []string{"electronvolt", "electronvolts"}, Prefixes: SIPrefixes},
+		Unit{Symbol: "BTU", Dimension: DimensionEnergy, Factor: 1055.05585262, Aliases: []string{"Btu"}},
+
+		Unit{Symbol: "N", Dimension: DimensionForce, Factor: 1, Aliases: []string{"newton", "newtons"}, Prefixes: SIPrefixes},
+		Unit{Symbol: "kgf", Dimension: DimensionForce, Factor: 9.80665},
+		Unit{Symbol: "lbf", Dimension: DimensionForce, Factor: 4.4482216152605},
+		Unit{Symbol: "dyn", Dimension: DimensionForce, Factor: 1e-5, Aliases: []string{"dyne", "dynes"}},
+
+		Unit{Symbol: "Pa", Dimension: DimensionPressure, Factor: 1, Aliases: []string{"pascal", "pascals"}, Prefixes: SIPrefixes},
+		Unit{Symbol: "bar", Dimension: DimensionPressure, Factor: 1e5, Aliases: []string{"bars"}, Prefixes: SIPrefixes},
+		Unit{Symbol: "atm", Dimension: DimensionPressure, Factor: 101325},
+		Unit{Symbol: "psi", Dimension: DimensionPressure, Factor: 6894.757293168},
+		Unit{Symbol: "mmHg", Dimension: DimensionPressure, Factor: 133.322387415},
+		Unit{Symbol: "Torr", Dimension: DimensionPressure, Factor: 101325.0 / 760, Aliases: []string{"torr"}},
+
+		Unit{Symbol: "Hz", Dimension: DimensionFrequency, Factor: 1, Aliases: []string{"hertz"}, Prefixes: SIPrefixes},
+		Unit{Symbol: "rpm", Dimension: DimensionFrequency, Factor: 1.0 / 60},
+
+		Unit{Symbol: "m/s", Dimension: DimensionSpeed, Factor: 1, Aliases: []string{"mps"}},
+		Unit{Symbol: "km/h", Dimension: DimensionSpeed, Factor: 1000.0 / 3600, Aliases: []string{"kph", "kmh"}},
+		Unit{Symbol: "mph", Dimension: DimensionSpeed, Factor: 0.44704},
+		Unit{Symbol: "kn", Dimension: DimensionSpeed, Factor: 1852.0 / 3600, Aliases: []string{"knot", "knots"}},
+
+		Unit{Symbol: "m²", Dimension: DimensionArea, Factor: 1, Aliases: []string{"m2", "sqm"}},
+		Unit{Symbol: "ha", Dimension: DimensionArea, Factor: 1e4, Aliases: []string{"hectare", "hectares"}},
+		Unit{Symbol: "acre", Dimension: DimensionArea, Factor: 4046.8564224, Aliases: []string{"acres"}},
+		Unit{Symbol: "亩", Dimension: DimensionArea, Factor: 10000.0 / 15, Aliases: []string{"畝", "市亩", "mu"}},
+		Unit{Symbol: "顷", Dimension: DimensionArea, Factor: 1e6 / 15, Aliases: []string{"頃", "市顷", "qing"}},
+
+		Unit{Symbol: "A", Dimension: DimensionCurrent, Factor: 1, Aliases: []string{"ampere", "amperes", "amp", "amps"}, Prefixes: SIPrefixes},
+	)
+	return r
+}
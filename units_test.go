@@ -0,0 +1,270 @@
+package utils_test
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	utils "github.com/jingyuexing/go-utils"
+)
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(1, math.Max(math.Abs(a), math.Abs(b)))
+}
+
+func TestUnitRegistryConvert(t *testing.T) {
+	cases := []struct {
+		value    float64
+		from, to string
+		expect   float64
+	}{
+		{1, "km", "m", 1000},
+		{2.5, "kilometers", "cm", 250000},
+		{1, "mi", "km", 1.609344},
+		{1, "KM", "m", 1000},
+		{1500, "mg", "g", 1.5},
+		{1, "lb", "oz", 16},
+		{250, "ml", "l", 0.25},
+		{250, "mL", "L", 0.25},
+		{2, "kL", "l", 2000},
+		{1500, "msec", "s", 1.5},
+		{2, "h", "min", 120},
+		{3, "w", "k", 30},
+		{1, "m", "k", 1000},
+		{1, "Mb", "MB", 0.125},
+		{8, "kb", "B", 1000},
+		{1, "Gb", "Mbit", 1000},
+	}
+	for _, c := range cases {
+		result, err := utils.DefaultUnitRegistry.Convert(c.value, c.from, c.to)
+		if err != nil {
+			t.Error(fmt.Sprintf("%v %s -> %s unexpected error %s", c.value, c.from, c.to, err))
+			continue
+		}
+		if !almostEqual(result, c.expect) {
+			t.Error(fmt.Sprintf("%v %s -> %s Expect: %v, but got %v", c.value, c.from, c.to, c.expect, result))
+		}
+	}
+
+	if _, err := utils.DefaultUnitRegistry.Convert(1, "km", "kg"); !errors.Is(err, utils.ErrDimensionMismatch) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrDimensionMismatch, err))
+	}
+	if _, err := utils.DefaultUnitRegistry.Convert(1, "parsec", "m"); !errors.Is(err, utils.ErrUnknownUnit) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrUnknownUnit, err))
+	}
+	if _, err := utils.DefaultUnitRegistry.Convert(1, "MM", "m"); !errors.Is(err, utils.ErrAmbiguousUnit) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrAmbiguousUnit, err))
+	}
+}
+
+func TestUnitRegistryRegister(t *testing.T) {
+	registry := utils.NewUnitRegistry()
+	registry.RegisterDimension("energy", "J")
+	err := registry.Register(utils.Unit{
+		Symbol:    "J",
+		Dimension: "energy",
+		Factor:    1,
+		Aliases:   []string{"joule", "joules"},
+		Prefixes:  utils.SIPrefixes,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry.MustRegister(utils.Unit{Symbol: "cal", Dimension: "energy", Factor: 4.184, Aliases: []string{"calorie"}})
+
+	if _, err := registry.Convert(1, "kcal", "kJ"); !errors.Is(err, utils.ErrUnknownUnit) {
+		t.Error("calorie was registered without prefixes")
+	}
+	result, err := registry.Convert(2, "kilojoules", "cal")
+	if err != nil || !almostEqual(result, 2000/4.184) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v %v", 2000/4.184, result, err))
+	}
+
+	if err := registry.Register(utils.Unit{Symbol: "eV", Dimension: "charge", Factor: 1}); !errors.Is(err, utils.ErrUnknownDimension) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrUnknownDimension, err))
+	}
+}
+
+func TestConversionFunctions(t *testing.T) {
+	c := &utils.ConversionFunctions{}
+	if !almostEqual(c.Length(1, "km", "m"), 1000) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", 1000, c.Length(1, "km", "m")))
+	}
+	if c.Length(1, "km", "kg") != -1 {
+		t.Error("Length should return -1 across dimensions")
+	}
+	if weight, err := c.Weight(2, "kg", "g"); err != nil || weight != 2000 {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v %v", 2000, weight, err))
+	}
+	if c.Size(3, "W", "K") != 30 {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", 30, c.Size(3, "W", "K")))
+	}
+	if storage, err := c.Storage(1, "GB", "MB"); err != nil || storage != 1000 {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v %v", 1000, storage, err))
+	}
+	if storage, err := c.Storage(1, "GiB", "MiB"); err != nil || storage != 1024 {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v %v", 1024, storage, err))
+	}
+	if storage, err := c.Storage(1, "KB", "bit"); err != nil || storage != 8000 {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v %v", 8000, storage, err))
+	}
+	if c.NetSpeed(1, "Gbps", "Mbps") != 1000 {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", 1000, c.NetSpeed(1, "Gbps", "Mbps")))
+	}
+	if !almostEqual(c.WeightEN(1, "pounds", "grams"), 453.59237) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", 453.59237, c.WeightEN(1, "pounds", "grams")))
+	}
+}
+
+func TestNonLinearConversion(t *testing.T) {
+	c := &utils.ConversionFunctions{}
+	cases := []struct {
+		convert  func(float64, string, string) (float64, error)
+		value    float64
+		from, to string
+		expect   float64
+	}{
+		{c.Temperature, 100, "°C", "°F", 212},
+		{c.Temperature, -40, "F", "C", -40},
+		{c.Temperature, 0, "K", "celsius", -273.15},
+		{c.Temperature, 491.67, "°R", "°C", 0},
+		{c.FuelEconomy, 5, "L/100km", "km/L", 20},
+		{c.FuelEconomy, 10, "L/100km", "mpg", 23.521458333},
+		{c.FuelEconomy, 30, "mpg", "L/100km", 7.840486111},
+		{c.Power, 30, "dBm", "W", 1},
+		{c.Power, 1, "mW", "dBm", 0},
+		{c.Power, 0, "dBW", "dBm", 30},
+		{c.Convert, 20, "dB", "ratio", 100},
+		{c.Convert, 50, "%", "dB", -3.010299957},
+	}
+	for _, item := range cases {
+		result, err := item.convert(item.value, item.from, item.to)
+		if err != nil {
+			t.Error(fmt.Sprintf("%v %s -> %s unexpected error %s", item.value, item.from, item.to, err))
+			continue
+		}
+		if math.Abs(result-item.expect) > 1e-6 {
+			t.Error(fmt.Sprintf("%v %s -> %s Expect: %v, but got %v", item.value, item.from, item.to, item.expect, result))
+		}
+	}
+
+	if _, err := c.FuelEconomy(0, "L/100km", "mpg"); !errors.Is(err, utils.ErrOutOfDomain) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrOutOfDomain, err))
+	}
+	if _, err := c.Power(0, "W", "dBm"); !errors.Is(err, utils.ErrOutOfDomain) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrOutOfDomain, err))
+	}
+}
+
+func TestUnitExpression(t *testing.T) {
+	c := &utils.ConversionFunctions{}
+	cases := []struct {
+		value    float64
+		from, to string
+		expect   float64
+	}{
+		{36, "km/h", "m/s", 10},
+		{10, "m/s", "km/h", 36},
+		{1, "kWh", "J", 3.6e6},
+		{1, "kg·m/s²", "N", 1},
+		{1, "kg*m*s^-2", "N", 1},
+		{2, "kg m / s2", "N", 2},
+		{1, "MB/s", "Mbps", 8},
+		{1, "MiB/s", "Mbps", 8.388608},
+		{1, "m³", "l", 1000},
+		{1, "m^3", "L", 1000},
+		{1, "(kg·m)/(s·s)", "kN", 0.001},
+		{60, "mph", "km/h", 96.56064},
+		{1, "ha", "m2", 10000},
+		{1, "kPa", "N/m²", 1000},
+		{1, "mi/gal", "km/L", 0.425143707},
+		{5, "L/100km", "mi/gal", 47.042916667},
+		{1, "W·s", "J", 1},
+		{120, "rpm", "1/s", 2},
+	}
+	for _, item := range cases {
+		result, err := c.Convert(item.value, item.from, item.to)
+		if err != nil {
+			t.Error(fmt.Sprintf("%v %s -> %s unexpected error %s", item.value, item.from, item.to, err))
+			continue
+		}
+		if !almostEqual(result, item.expect) && math.Abs(result-item.expect) > 1e-6 {
+			t.Error(fmt.Sprintf("%v %s -> %s Expect: %v, but got %v", item.value, item.from, item.to, item.expect, result))
+		}
+	}
+
+	_, err := c.Convert(1, "km/h", "kg")
+	if !errors.Is(err, utils.ErrDimensionMismatch) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrDimensionMismatch, err))
+	}
+	if _, err := c.Convert(1, "°C/s", "K/s"); err == nil {
+		t.Error("non-linear units should not be combined")
+	}
+	if _, err := c.Convert(1, "parsec/s", "m/s"); !errors.Is(err, utils.ErrUnknownUnit) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", utils.ErrUnknownUnit, err))
+	}
+
+	dimension, err := utils.DefaultUnitRegistry.Dimension("kWh")
+	if err != nil || dimension.String() != "kg·m^2·s^-2" {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s %v", "kg·m^2·s^-2", dimension, err))
+	}
+}
+
+func TestChineseUnits(t *testing.T) {
+	conversion := utils.ConversionFunctions{}
+	cases := []struct {
+		value    float64
+		from, to string
+		expected float64
+	}{
+		{1, "斤", "g", 500},
+		{1, "斤", "两", 10},
+		{3, "钱", "两", 0.3},
+		{1, "kg", "jin", 2},
+		{1, "里", "m", 500},
+		{1, "丈", "尺", 10},
+		{1, "尺", "寸", 10},
+		{3, "尺", "m", 1},
+		{1, "顷", "亩", 100},
+		{15, "亩", "ha", 1},
+		{1, "亿", "万", 1e4},
+		{1, "兆", "亿", 1e4},
+		{2.5, "yi", "m", 250},
+	}
+	for _, c := range cases {
+		got, err := conversion.Convert(c.value, c.from, c.to)
+		if err != nil || !almostEqual(got, c.expected) {
+			t.Error(fmt.Sprintf("Expect: %v %s, but got %v (%v)", c.expected, c.to, got, err))
+		}
+	}
+	if got := conversion.Size(3, "亿", "W"); got != 3e4 {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", 3e4, got))
+	}
+	if got, _ := conversion.Area(1, "亩", "m²"); !almostEqual(got, 10000.0/15) {
+		t.Error(fmt.Sprintf("Expect: %v, but got %v", 10000.0/15, got))
+	}
+}
+
+func TestFormatChineseCount(t *testing.T) {
+	cases := []struct {
+		value    float64
+		opts     []utils.DataOption[utils.ChineseCountFormat]
+		expected string
+	}{
+		{9999, nil, "9999"},
+		{35000, nil, "3.5万"},
+		{120000000, nil, "1.2亿"},
+		{-12345678, nil, "-1234.57万"},
+		{99999999, nil, "1亿"},
+		{3.2e12, nil, "3.2兆"},
+		{123456, []utils.DataOption[utils.ChineseCountFormat]{utils.WithCountPrecision(0)}, "12万"},
+		{35000, []utils.DataOption[utils.ChineseCountFormat]{utils.WithChineseNumerals(false)}, "三点五万"},
+		{150000000, []utils.DataOption[utils.ChineseCountFormat]{utils.WithChineseNumerals(true)}, "壹点伍亿"},
+	}
+	for _, c := range cases {
+		if got := utils.FormatChineseCount(c.value, c.opts...); got != c.expected {
+			t.Error(fmt.Sprintf("Expect: %s, but got %s", c.expected, got))
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"log"
 	"os"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -40,41 +41,23 @@ func ToString(val any) string {
 	return valstr
 }
 
+// Template replaces the placeholders of source with values from data. See
+// CompileTemplate for the placeholder syntax; "?" placeholders take the
+// values in key order. Placeholders without a value are kept as they are.
 func Template(source string, data map[string]any, placeholder string) string {
-	tempSyntax := "{}"
-	if placeholder != "" {
-		tempSyntax = placeholder
+	template, err := CompileTemplate(source, placeholder)
+	if err != nil {
+		return source
 	}
-	key := ""
-	sourceCopy := &source
-	for k, val := range data {
-		valStr := ""
-		valStr = ToString(val)
-		switch tempSyntax {
-		case "{}":
-			key = strings.Join([]string{"{", k, "}"}, "")
-		case "[]":
-			key = strings.Join([]string{"[", k, "]"}, "")
-		case "()":
-			key = strings.Join([]string{"(", k, ")"}, "")
-		case ":", "@", "#", "$":
-			key = strings.Join([]string{tempSyntax, k}, "")
-		case "?":
-			key = tempSyntax
-		default:
-			if (len(tempSyntax) & 1) == 1 {
-				key = strings.Join([]string{tempSyntax, k}, "")
-				break
-			}
-			if (len(tempSyntax) & 1) == 0 {
-				halfLength := len(tempSyntax) / 2
-				key = strings.Join([]string{tempSyntax[:halfLength], k, tempSyntax[halfLength:]}, "")
-			}
-
-		}
-		*sourceCopy = strings.Replace(*sourceCopy, key, valStr, 1)
+	var values any = data
+	if template.positional {
+		values = templateValues(data)
+	}
+	result, err := template.Render(values)
+	if err != nil {
+		return source
 	}
-	return *sourceCopy
+	return result
 }
 
 func InRange(val int, min int, max int) bool {
@@ -100,20 +83,6 @@ func IsAlphanum(char rune) bool {
 	return IsAlphabet(char) || IsNumberic(char)
 }
 
-func getValidRef(refname string, symbol rune) string {
-	word := []string{}
-	for _, char := range refname {
-		if char != symbol && int(char) != ' ' {
-			if IsAlphanum(char) {
-				word = append(word, string(char))
-			} else if int(char) > 0xff {
-				word = append(word, string(char))
-			}
-		}
-	}
-	return strings.Join(word, "")
-}
-
 func toComment(val string, commentSymbol []string) string{
     if len(commentSymbol) & 1 == 0 {
         return strings.Join([]string{commentSymbol[0],val,commentSymbol[1]},"")
@@ -121,83 +90,36 @@ func toComment(val string, commentSymbol []string) string{
     return commentSymbol[0] + val;
 }
 
-func deepReplace(target map[string]string, symbol rune, text string) string {
-	clone := strings.Clone(text)
-	list := strings.Split(clone, " ")
-	for i := 0; i < len(list); i++ {
-		token := list[i]
-		if !strings.Contains(clone, string(symbol)) {
-			return clone
-		}
-		if strings.Contains(token, string(symbol)) {
-			refrence := strings.Split(token, string(symbol))
-			for _, refName := range refrence {
-				if refName == "" {
-					continue
-				}
-				validRef := getValidRef(refName, rune(symbol))
-				refString, ok := target[validRef]
-				if !ok {
-					refString = toComment(validRef,[]string{"/*","*/"})
-				}
-				values := deepReplace(target, symbol, refString)
-				clone = strings.Replace(clone, string(symbol)+refName, values, 1)
-			}
-		}
-	}
-	return clone
-}
-
+// ReferenceString returns a function that looks up an entry of target and
+// expands the references (@name, or symbol followed by a name) it contains.
+// A missing reference becomes a /*name*/ comment; a missing entry or a
+// reference cycle yields "". Every call reads target again, use
+// NewReferenceResolver to cache the results and get the errors.
 func ReferenceString(target map[string]string, symbol rune) func(string) string {
 	if symbol == 0 {
 		symbol = '@'
 	}
-	replace := func(text string) string {
-		return deepReplace(target, symbol, text)
-	}
-	getItem := func(key string) string {
-		if val, ok := target[key]; ok {
-			return replace(val)
+	return func(key string) string {
+		value, err := NewReferenceResolver(target, WithReferenceSymbol(symbol)).Get(key)
+		if err != nil {
+			return ""
 		}
-		return ""
+		return value
 	}
-	return getItem
 }
 
+// FindVariableNames returns the name of every placeholder of text in
+// order, see TemplateInfo for the details of each placeholder.
 func FindVariableNames(text string, delimiter string) []string {
-	leftSyombol := ""
-	rightSyombol := ""
-	variables := []string{}
-	maxLength := len(text)
-	if maxLength&0 == 0 {
-		half := len(delimiter) / 2
-		leftSyombol = delimiter[:half]
-		rightSyombol = delimiter[half:]
-	} else {
-		leftSyombol = delimiter
+	variables, err := TemplateInfo(text, delimiter)
+	if err != nil {
+		return []string{}
 	}
-	idx := 0
-	for idx < maxLength {
-		nameCache := make([]string, 0)
-		if string(text[idx]) == leftSyombol {
-			n := idx + 1
-			for n < maxLength {
-				if rightSyombol != "" && string(text[n]) == rightSyombol {
-					break
-				} else if string(text[n]) == " " {
-					break
-				}
-				nameCache = append(nameCache, string(text[n]))
-				n++
-			}
-			variables = append(variables, strings.Join(nameCache, ""))
-			//
-			nameCache = make([]string, 0)
-			idx = n
-		}
-		idx++
+	names := make([]string, 0, len(variables))
+	for _, variable := range variables {
+		names = append(names, variable.Name)
 	}
-	return variables
+	return names
 }
 
 type Cookie struct {
@@ -206,91 +128,77 @@ type Cookie struct {
 	joiner    string
 }
 
-func pathParse(target string) []string {
-	PathSlice := strings.Split(target, "/")
-	obj := make([]string, 0)
-	for i := 0; i < len(PathSlice); i++ {
-		key := PathSlice[i]
-		if key != "" {
-			if strings.HasPrefix(key, ":") {
-				if strings.HasSuffix(key, ":") {
-					obj = append(obj, key[1:len(key)-1])
-				} else {
-					obj = append(obj, key[1:])
-				}
-			} else if strings.HasPrefix(key, "{") && strings.HasSuffix(key, "}") {
-				obj = append(obj, key[1:len(key)-1])
-
-			} else {
-				obj = append(obj, key)
-			}
-		}
-	}
-	return obj
-}
-
+// CreateNestedObject builds a map holding value at the path keys, such as
+// {"a": {"b": value}} for "a.b". A pointer value is stored dereferenced, a
+// nil pointer as nil. Every key becomes a map[string]interface{} level that
+// takes any key and value, so no input fails; the empty map returned on an
+// error is only a safeguard.
 func CreateNestedObject(keys string, value interface{}, delimiter string) map[string]interface{} {
 	if delimiter == "" {
 		delimiter = "."
 	}
-	keyList := strings.Split(keys, delimiter)
+	stored := reflect.ValueOf(value)
+	if stored.Kind() == reflect.Pointer {
+		stored = stored.Elem()
+	}
 	result := make(map[string]interface{})
-	current := reflect.ValueOf(result)
-
-	for i, key := range keyList {
-		if i == len(keyList)-1 {
-			current.SetMapIndex(reflect.ValueOf(key), reflect.ValueOf(value).Elem())
-		} else {
-			if current.MapIndex(reflect.ValueOf(key)).IsValid() {
-				current = current.MapIndex(reflect.ValueOf(key))
-			} else {
-				newMap := make(map[string]interface{})
-				current.SetMapIndex(reflect.ValueOf(key), reflect.ValueOf(newMap))
-				current = reflect.ValueOf(newMap)
-			}
-		}
+	root := reflect.ValueOf(result)
+	if _, err := setNestedValue(root, root.Type(), strings.Split(keys, delimiter), stored); err != nil {
+		return map[string]interface{}{}
 	}
-
 	return result
 }
 
 func AccessNested(data any, path string, delimiter string) any {
-	keys := strings.Split(path, delimiter)
-	value := reflect.ValueOf(data)
-
-	if value.Kind() == reflect.Pointer {
-		value = reflect.Indirect(value)
-	}
+	value, _ := lookupNested(data, strings.Split(path, delimiter))
+	return value
+}
 
+// lookupNested follows keys through maps, struct fields, slice indexes and
+// pointers, converting them like SetNested, and reports whether the whole
+// path exists.
+func lookupNested(data any, keys []string) (any, bool) {
+	value := reflect.ValueOf(data)
 	for _, key := range keys {
+		for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+			value = value.Elem()
+		}
 		if !value.IsValid() {
-			return nil
+			return nil, false
 		}
 
 		switch value.Kind() {
-		case reflect.Pointer:
-			value = reflect.Indirect(value)
 		case reflect.Map:
-			mapValue := value.MapIndex(reflect.ValueOf(key))
+			mapKey, err := nestedMapKey(key, value.Type().Key())
+			if err != nil {
+				return nil, false
+			}
+			mapValue := value.MapIndex(mapKey)
 			if !mapValue.IsValid() {
-				return nil
+				return nil, false
 			}
 			value = mapValue
 		case reflect.Struct:
-			field := value.FieldByName(key)
+			field := nestedField(value, key)
 			if !field.IsValid() {
-				return nil
+				return nil, false
 			}
 			value = field
+		case reflect.Slice, reflect.Array:
+			index, err := nestedIndex(key, value.Len())
+			if err != nil || index >= value.Len() {
+				return nil, false
+			}
+			value = value.Index(index)
 		default:
-			return nil
+			return nil, false
 		}
 	}
 
 	if value.IsValid() && value.CanInterface() {
-		return value.Interface()
+		return value.Interface(), true
 	}
-	return nil
+	return nil, false
 }
 
 func NestedObject[T any](target T, path string, cb func(target reflect.Value, key string)) error {
@@ -351,16 +259,18 @@ func NestedObject[T any](target T, path string, cb func(target reflect.Value, ke
 	return nil
 }
 
+// GetPathValue matches realPath against the route pattern raw and returns
+// its parameters, or an empty map when it does not match.
 func GetPathValue(raw string, realPath string) map[string]string {
-	rawObj := pathParse(raw)
-	realObj := pathParse(realPath)
-	valObj := make(map[string]string)
-	for idx, pathName := range rawObj {
-		if rawObj[idx] != realObj[idx] {
-			valObj[pathName] = realObj[idx]
-		}
+	route, err := CompileRoute(raw)
+	if err != nil {
+		return map[string]string{}
+	}
+	params, ok := route.Match(realPath)
+	if !ok {
+		return map[string]string{}
 	}
-	return valObj
+	return params
 }
 
 func (c *Cookie) NewCookie(cookie string, delimiter string, joiner string) *Cookie {
@@ -369,13 +279,22 @@ func (c *Cookie) NewCookie(cookie string, delimiter string, joiner string) *Cook
 	var cookiesList = strings.Split(cookie, c.delimiter)
 	c.query = make(map[string]string)
 	for _, item := range cookiesList {
-		keyAndValue := strings.Split(item, c.joiner)
-		c.query[keyAndValue[0]] = keyAndValue[1]
+		// values may contain the joiner, as base64 padding does
+		key, value, _ := strings.Cut(strings.TrimSpace(item), c.joiner)
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		value, _ = unquoteCookieValue(strings.TrimSpace(value))
+		c.query[key] = value
 	}
 	return c
 }
 
 func (c *Cookie) PutOne(key string, val string) *Cookie {
+	if c.query == nil {
+		c.query = make(map[string]string)
+	}
 	c.query[key] = val
 	return c
 }
@@ -386,8 +305,13 @@ func (c *Cookie) GetAll() map[string]string {
 
 func (c *Cookie) ToString() string {
 	var cookieSplit []string
-	for key, val := range c.query {
-		cookieSplit = append(cookieSplit, strings.Join([]string{key, val}, c.joiner))
+	keys := make([]string, 0, len(c.query))
+	for key := range c.query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		cookieSplit = append(cookieSplit, strings.Join([]string{key, c.query[key]}, c.joiner))
 	}
 	return strings.Join(cookieSplit, c.delimiter)
 }
@@ -412,27 +336,6 @@ func ToLowerCamelCase(s string) string {
 	return strings.ToLower(s[:1]) + s[1:]
 }
 
-func Map2Struct(source map[string]any, bindingTarget any) {
-	typeEle := reflect.TypeOf(bindingTarget).Elem()
-	ValEle := reflect.ValueOf(bindingTarget).Elem()
-	if ValEle.Kind() == reflect.Struct && ValEle.CanSet() {
-		for i := 0; i < typeEle.NumField(); i++ {
-			field := typeEle.Field(i)
-			value, ok := source[ToLowerCamelCase(field.Name)]
-			if ok && field.Type.Kind() == reflect.Ptr && field.Type.Elem().Kind() == reflect.Struct {
-				ptr := ValEle.FieldByName(field.Name)
-				if ptr.IsNil() {
-					newStructPtr := reflect.New(field.Type.Elem())
-					ptr.Set(newStructPtr)
-				}
-				Map2Struct(source, ptr.Interface())
-			} else {
-				ValEle.FieldByName(field.Name).Set(reflect.ValueOf(value))
-			}
-		}
-	}
-}
-
 func TimeDuration(duration string) (time.Time, error) {
 	const (
 		SECOND        uint64 = 1
@@ -454,6 +357,9 @@ func TimeDuration(duration string) (time.Time, error) {
 	splitDuration := strings.Split(duration, " ")
 	durationSecond := uint64(0)
 	for _, val := range splitDuration {
+		if val == "" {
+			continue
+		}
 		unit := val[len(val)-1:]
 		num, _ := strconv.Atoi(val[:len(val)-1])
 		if unitSeconds, ok := uints[unit]; ok {
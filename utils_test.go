@@ -21,6 +21,10 @@ func TestFormat(t *testing.T) {
 		fmt.Printf(result + "\n")
 	}
 
+	if empty := utils.Template("Hi {name}!", map[string]any{"name": ""}, ""); empty != "Hi !" {
+		t.Error(fmt.Sprintf("Expect %s, but got %s", "Hi !", empty))
+	}
+
 	result2 := utils.Template("? ?", map[string]any{
 		"a": 1,
 		"b": 2,
@@ -89,13 +93,18 @@ func TestCookieParse(t *testing.T) {
 	cookie.NewCookie("a=b&c=23&k=66", "&", "=")
 	cookie.PutOne("s", "v")
 	fmt.Printf("%s\n", cookie.ToString())
+
+	cookie.NewCookie(" token=YWI= ; flag; c=\"1\"", ";", "=")
+	if cookie.ToString() != "c=1;flag=;token=YWI=" {
+		t.Error(fmt.Sprintf("Expect: %s, but got %s", "c=1;flag=;token=YWI=", cookie.ToString()))
+	}
 }
 
 func TestDebounce(t *testing.T) {
 
 }
 
-func TestgetPathValue(t *testing.T) {
+func TestGetPathValue(t *testing.T) {
 	result := utils.GetPathValue("/user/:id", "/user/12")
 	if result["id"] != "12" {
 		t.Error("Not Pass")
@@ -367,9 +376,9 @@ func TestOmit(t *testing.T) {
 		Age:     20,
 		Address: "BC",
 	}
-	result := utils.Omit(p, []string{"Name"})
+	result := utils.Omit(p, "Name")
 
-	if _, ok := result["Name"]; ok {
+	if _, ok := result["name"]; ok {
 		t.Error("Omit has wrong")
 	}
 	fmt.Printf("%#v\n", result)
@@ -386,8 +395,8 @@ func TestPick(t *testing.T) {
 		Age:     20,
 		Address: "BC",
 	}
-	result := utils.Pick(p, []string{"Name"})
-	if len(result) > 1 {
+	result := utils.Pick(p, "Name")
+	if len(result) != 1 || result["name"] != "william" {
 		t.Error("Pick has wrong")
 	}
 	fmt.Printf("%#v\n", result)
@@ -450,6 +459,10 @@ func TestReferenceString(t *testing.T) {
 	fmt.Printf("%s\n", utils.FindVariableNames(ref("updateByName"), "{}"))
 	fmt.Printf("%s\n", ref("error"))
 
+	refMap["table"] = "account"
+	if ref("select") != "select * from account" {
+		t.Error(fmt.Sprintf("TestReferenceString Expect: %s, but got %s", "select * from account", ref("select")))
+	}
 }
 
 func TestBuffer(t *testing.T) {
@@ -615,6 +628,15 @@ func TestBigNumber(t *testing.T){
     if result.String() != "0.500000" {
         t.Error(fmt.Sprintf(" 1 / 2 should be 0.5, but got %s\n",result.String()))
     }
+    if quotient := utils.NewBigNumber("7.5").Divide(utils.NewBigNumber("1.25")); quotient.String() != "6.000000" {
+        t.Error(fmt.Sprintf(" 7.5 / 1.25 should be 6, but got %s\n",quotient.String()))
+    }
+    if rounded := utils.NewBigNumber("2.345").Round(2, utils.RoundHalfEven); rounded.String() != "2.34" {
+        t.Error(fmt.Sprintf(" 2.345 should round half even to 2.34, but got %s\n",rounded.String()))
+    }
+    if fixed := utils.NewBigNumber("9.999").ToFixed(2); fixed != "10.00" {
+        t.Error(fmt.Sprintf(" 9.999 to fixed 2 should be 10.00, but got %s\n",fixed))
+    }
     nums16 := utils.NewBigNumber("-12")
     if nums16.AbsoluteValue().String() != "12" {
         t.Error(fmt.Sprintf("the -12 absolute value should be 12, but got %s\n",nums16.AbsoluteValue().String()))